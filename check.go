@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of monitoring plugins, as expected by Nagios & Icinga.
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkUnknown  = 3
+)
+
+var checkStates = map[int]string{
+	checkOK:       "OK",
+	checkWarning:  "WARNING",
+	checkCritical: "CRITICAL",
+	checkUnknown:  "UNKNOWN",
+}
+
+// checkStatus formats a plugin status line for the given exit code.
+func checkStatus(code int, summary string, perfdata []string) string {
+	s := fmt.Sprintf("DUF %s - %s", checkStates[code], summary)
+	if len(perfdata) > 0 {
+		s += " | " + strings.Join(perfdata, " ")
+	}
+	return s
+}
+
+// perfData formats a single performance data value, with its warning and
+// critical levels derived from the usage thresholds.
+func perfData(label string, used, total uint64, unit string, thresholds string) string {
+	yellowUsage, _ := strconv.ParseFloat(strings.Split(thresholds, ",")[0], 64)
+	redUsage, _ := strconv.ParseFloat(strings.Split(thresholds, ",")[1], 64)
+
+	return fmt.Sprintf("'%s'=%d%s;%d;%d;0;%d",
+		strings.ReplaceAll(label, "'", "''"),
+		used, unit,
+		uint64(yellowUsage*float64(total)),
+		uint64(redUsage*float64(total)),
+		total)
+}
+
+// checkMounts evaluates the avail, usage and inode usage thresholds against
+// the grouped mounts. It returns the plugin exit code and status line.
+func checkMounts(deviceMounts map[string][]Mount) (int, string) {
+	var problems, perfdata []string
+	level := levelOK
+	count := 0
+
+	vis := map[string]struct{}{}
+	for _, devType := range groups {
+		for _, v := range deviceMounts[devType] {
+			if _, ok := vis[v.Mountpoint]; ok {
+				continue
+			}
+			vis[v.Mountpoint] = struct{}{}
+			count++
+
+			var reasons []string
+			mountLevel := levelOK
+			if v.Total > 0 {
+				usage := usageRatio(v.Used, v.Total)
+				if l := usageLevel(usage, *usageThreshold); l > levelOK {
+					reasons = append(reasons, fmt.Sprintf("%.1f%% used", usage*100))
+					mountLevel = max(mountLevel, l)
+				}
+				if l := availLevel(v.Free); l > levelOK {
+					reasons = append(reasons, fmt.Sprintf("%s avail", sizeToString(v.Free)))
+					mountLevel = max(mountLevel, l)
+				}
+
+				perfdata = append(perfdata, perfData(v.Mountpoint, v.Used, v.Total, "B", *usageThreshold))
+			}
+			if v.Inodes > 0 {
+				inodeUsage := usageRatio(v.InodesUsed, v.Inodes)
+				if l := usageLevel(inodeUsage, *inodesUsageThreshold); l > levelOK {
+					reasons = append(reasons, fmt.Sprintf("%.1f%% inodes used", inodeUsage*100))
+					mountLevel = max(mountLevel, l)
+				}
+
+				perfdata = append(perfdata, perfData(v.Mountpoint+" inodes", v.InodesUsed, v.Inodes, "", *inodesUsageThreshold))
+			}
+
+			if mountLevel > levelOK {
+				problems = append(problems, fmt.Sprintf("%s: %s", v.Mountpoint, strings.Join(reasons, ", ")))
+			}
+			level = max(level, mountLevel)
+		}
+	}
+
+	if count == 0 {
+		return checkUnknown, checkStatus(checkUnknown, "no mounts to check", nil)
+	}
+
+	code := checkOK
+	switch level {
+	case levelCritical:
+		code = checkCritical
+	case levelWarning:
+		code = checkWarning
+	}
+
+	summary := strings.Join(problems, "; ")
+	if code == checkOK {
+		suffix := "mount"
+		if count > 1 {
+			suffix = "mounts"
+		}
+		summary = fmt.Sprintf("%d %s checked", count, suffix)
+	}
+
+	return code, checkStatus(code, summary, perfdata)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// renderCSV writes the selected columns of all grouped mounts as
+// comma-separated values, or tab-separated values if comma is '\t'. Trends are
+// drawn in the style styleName.
+func renderCSV(w io.Writer, deviceMounts map[string][]Mount, cols []int, sortBy int, samples []Sample, comma rune, human bool, styleName string) error {
+	var m []Mount
+	for _, devType := range groups {
+		m = append(m, deviceMounts[devType]...)
+	}
+	sortMounts(m, sortBy, samples)
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	header := make([]string, 0, len(cols))
+	for _, c := range cols {
+		header = append(header, columns[c-1].ID)
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("error writing the csv output: %s", err)
+	}
+
+	for _, v := range m {
+		record := make([]string, 0, len(cols))
+		for _, c := range cols {
+			record = append(record, columnValue(v, c, human, samples, styleName))
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("error writing the csv output: %s", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing the csv output: %s", err)
+	}
+	return nil
+}
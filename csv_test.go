@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRenderCSV(t *testing.T) {
+	v := Mount{Device: "/dev/sda1", Mountpoint: "/", DeviceType: localDevice, Total: 100, Used: 90}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start, Usages: map[string]Usage{"/": {Used: 10, Total: 100}}},
+		{Time: start.Add(time.Hour), Usages: map[string]Usage{"/": {Used: 90, Total: 100}}},
+	}
+	cols := []int{1, 3, 15}
+
+	var tt = []struct {
+		styleName string
+		comma     rune
+		human     bool
+		expected  string
+	}{
+		{styleName: "unicode", comma: ',', human: true, expected: "mountpoint,used,trend\n/,90B,▁█\n"},
+		{styleName: "ascii", comma: ',', human: true, expected: "mountpoint,used,trend\n/,90B,_#\n"},
+		{styleName: "ascii", comma: '\t', expected: "mountpoint\tused\ttrend\n/\t90\t_#\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.styleName, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := renderCSV(&buf, map[string][]Mount{localDevice: {v}}, cols, 1, samples, tc.comma, tc.human, tc.styleName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+)
+
+// filesystemKey returns a key identifying the filesystem mounted by v, or an
+// empty string if it can't be identified. Filesystems are identified by their
+// device numbers, or by their block devices.
+func filesystemKey(v Mount) string {
+	switch {
+	case v.Devno != "":
+		return v.Host + "\x00" + v.Devno
+	case strings.HasPrefix(v.Device, "/dev/"):
+		return v.Host + "\x00" + v.Device
+	default:
+		return ""
+	}
+}
+
+// dedupeMounts collapses the mounts of the same filesystem into a single mount,
+// listing the other mount points in AlsoMountedAt. The primary mount is the
+// first one that isn't a bind mount, preferring the shortest mount point.
+func dedupeMounts(m []Mount) []Mount {
+	primaries := map[string]int{}
+	for i, v := range m {
+		key := filesystemKey(v)
+		if key == "" {
+			continue
+		}
+
+		p, ok := primaries[key]
+		if !ok || isBetterPrimary(v, m[p]) {
+			primaries[key] = i
+		}
+	}
+
+	also := map[string][]string{}
+	for i, v := range m {
+		key := filesystemKey(v)
+		if key != "" && primaries[key] != i {
+			also[key] = append(also[key], v.Mountpoint)
+		}
+	}
+
+	var dm []Mount
+	for i, v := range m {
+		key := filesystemKey(v)
+		switch {
+		case key == "":
+			dm = append(dm, v)
+		case primaries[key] == i:
+			v.AlsoMountedAt = also[key]
+			dm = append(dm, v)
+		}
+	}
+
+	return dm
+}
+
+// dedupeVisibleMounts dedupes the mounts left after applying the filters, so
+// mount points that are filtered out aren't listed in AlsoMountedAt either.
+func dedupeVisibleMounts(m []Mount, filters FilterOptions) []Mount {
+	var vm []Mount
+	for _, v := range m {
+		if len(filterMounts([]Mount{v}, filters)) > 0 {
+			vm = append(vm, v)
+		}
+	}
+
+	return dedupeMounts(vm)
+}
+
+// isBetterPrimary returns true if v is a better primary mount of a filesystem
+// than the mount p.
+func isBetterPrimary(v, p Mount) bool {
+	if isBindMount(v) != isBindMount(p) {
+		return !isBindMount(v)
+	}
+	return len(v.Mountpoint) < len(p.Mountpoint)
+}
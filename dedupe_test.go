@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupeMounts(t *testing.T) {
+	m := []Mount{
+		{Device: "/dev/sda1", Devno: "8:1", Root: "/srv", Mountpoint: "/var/www", BindSource: "/srv"},
+		{Device: "/dev/sda1", Devno: "8:1", Root: "/", Mountpoint: "/"},
+		{Device: "/dev/sdb1", Devno: "8:17", Root: "/@home", Mountpoint: "/home"},
+		{Device: "/dev/sdb1", Devno: "8:17", Root: "/@", Mountpoint: "/data"},
+		{Device: "tmpfs", Mountpoint: "/tmp"},
+		{Device: "tmpfs", Mountpoint: "/run"},
+		{Device: "/dev/sdc1", Mountpoint: "/mnt/a"},
+		{Device: "/dev/sdc1", Mountpoint: "/b"},
+	}
+
+	expected := []Mount{
+		{Device: "/dev/sda1", Devno: "8:1", Root: "/", Mountpoint: "/", AlsoMountedAt: []string{"/var/www"}},
+		{Device: "/dev/sdb1", Devno: "8:17", Root: "/@home", Mountpoint: "/home", AlsoMountedAt: []string{"/data"}},
+		{Device: "tmpfs", Mountpoint: "/tmp"},
+		{Device: "tmpfs", Mountpoint: "/run"},
+		{Device: "/dev/sdc1", Mountpoint: "/b", AlsoMountedAt: []string{"/mnt/a"}},
+	}
+	if dm := dedupeMounts(m); !reflect.DeepEqual(dm, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dm)
+	}
+}
+
+func TestDedupeVisibleMounts(t *testing.T) {
+	m := []Mount{
+		{Device: "/dev/sda1", Devno: "8:1", Mountpoint: "/", DeviceType: localDevice, Blocks: 1, BlockSize: 1},
+		{Device: "/dev/sda1", Devno: "8:1", Mountpoint: "/mnt", DeviceType: localDevice, Blocks: 1, BlockSize: 1},
+		{Device: "/dev/sda1", Devno: "8:1", Mountpoint: "/var/lib/docker", DeviceType: localDevice, Blocks: 1, BlockSize: 1},
+		{Device: "/dev/sdb1", Devno: "8:17", Mountpoint: "/srv", DeviceType: localDevice, Blocks: 1, BlockSize: 1},
+		{Device: "/dev/sdb1", Devno: "8:17", Mountpoint: "/backup", DeviceType: localDevice, Blocks: 1, BlockSize: 1},
+	}
+	filters := FilterOptions{
+		HiddenMountPoints: map[string]struct{}{"/var/lib/*": {}, "/backup": {}},
+	}
+
+	expected := []Mount{
+		{Device: "/dev/sda1", Devno: "8:1", Mountpoint: "/", DeviceType: localDevice, Blocks: 1, BlockSize: 1, AlsoMountedAt: []string{"/mnt"}},
+		{Device: "/dev/sdb1", Devno: "8:17", Mountpoint: "/srv", DeviceType: localDevice, Blocks: 1, BlockSize: 1},
+	}
+	if dm := dedupeVisibleMounts(m, filters); !reflect.DeepEqual(dm, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dm)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	networkFsTypes = map[string]bool{
+		"afs": true, "cifs": true, "coda": true, "ftpfs": true, "ncpfs": true, "nfs": true,
+		"nfs4": true, "smbfs": true, "smb3": true, "sshfs": true, "fuse.sshfs": true,
+	}
+	specialFsTypes = map[string]bool{
+		"autofs": true, "cgroup": true, "cgroup2": true, "devfs": true, "devpts": true,
+		"devtmpfs": true, "efivarfs": true, "fdescfs": true, "linprocfs": true, "mqueue": true,
+		"proc": true, "pstore": true, "ramfs": true, "securityfs": true, "sysfs": true,
+		"tmpfs": true, "tracefs": true, "usbfs": true,
+	}
+
+	blocksHeader = regexp.MustCompile(`^(\d+)([KMGTPE]?)-blocks$`)
+)
+
+// guessDeviceType determines the device type of a mount from its device and
+// filesystem type, for mounts read without access to the filesystem.
+func guessDeviceType(device, fstype string) string {
+	fstype = strings.ToLower(fstype)
+	switch {
+	case networkFsTypes[fstype]:
+		return networkDevice
+	case specialFsTypes[fstype]:
+		return specialDevice
+	case strings.HasPrefix(fstype, "fuse"):
+		return fuseDevice
+	case fstype != "":
+		return localDevice
+	}
+
+	// without a filesystem type, fall back to the device's name
+	switch {
+	case specialFsTypes[device] || device == "udev" || device == "none":
+		return specialDevice
+	case strings.HasPrefix(device, "//") || strings.Contains(device, ":/"):
+		return networkDevice
+	default:
+		return localDevice
+	}
+}
+
+// parseDfNumber parses a number printed by df, which prints "-" for values a
+// filesystem doesn't support.
+func parseDfNumber(s string) (uint64, error) {
+	if s == "-" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// isDfNumber returns true if s is a number or a percentage printed by df.
+func isDfNumber(s string) bool {
+	_, err := parseDfNumber(strings.TrimSuffix(s, "%"))
+	return err == nil
+}
+
+// parseDf parses the output of POSIX df -P, optionally with -T (filesystem
+// types) or -i (inodes instead of blocks). The columns are identified by their
+// headers. Lines that can't be parsed are skipped and returned as warnings.
+func parseDf(r io.Reader) ([]Mount, []string, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("missing df header")
+	}
+
+	header := strings.Fields(scanner.Text())
+	var blockSize uint64
+	var hasBlocks, hasInodes bool
+	cols := -1
+	textCols := 0
+	for i, h := range header {
+		if match := blocksHeader.FindStringSubmatch(h); match != nil {
+			var err error
+			blockSize, err = stringToSize(match[1] + match[2])
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid df header %s: %s", h, err)
+			}
+			hasBlocks = true
+		}
+		switch strings.ToLower(h) {
+		case "filesystem", "type":
+			if textCols == i {
+				textCols++
+			}
+		case "inodes", "iused", "ifree":
+			hasInodes = true
+		case "mounted":
+			cols = i
+		}
+	}
+	if cols < 0 || textCols == 0 || (!hasBlocks && !hasInodes) {
+		return nil, nil, fmt.Errorf("unknown df header: %s", scanner.Text())
+	}
+	numCols := cols - textCols
+
+	var m []Mount
+	var warnings []string
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// device names and mount points may contain spaces, so find the first
+		// run of numbers followed by the mount point
+		start := -1
+		for j := textCols; j+numCols < len(fields); j++ {
+			ok := true
+			for _, f := range fields[j : j+numCols] {
+				if !isDfNumber(f) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				start = j
+				break
+			}
+		}
+		if start < 0 {
+			warnings = append(warnings, fmt.Sprintf("invalid df line: %s", scanner.Text()))
+			continue
+		}
+
+		row := make([]string, cols)
+		row[0] = strings.Join(fields[:start-textCols+1], " ")
+		copy(row[1:], fields[start-textCols+1:start+numCols])
+
+		v := Mount{Mountpoint: strings.Join(fields[start+numCols:], " ")}
+		var inodesKnown bool
+		var err error
+		for i, h := range header[:cols] {
+			var n uint64
+			switch lh := strings.ToLower(h); {
+			case lh == "filesystem":
+				v.Device = row[i]
+			case lh == "type":
+				v.Fstype = row[i]
+			case blocksHeader.MatchString(h):
+				n, err = parseDfNumber(row[i])
+				v.Blocks = n
+				v.Total = n * blockSize
+			case lh == "used":
+				n, err = parseDfNumber(row[i])
+				v.Used = n * blockSize
+			case lh == "available" || lh == "avail":
+				n, err = parseDfNumber(row[i])
+				v.Free = n * blockSize
+			case lh == "inodes":
+				v.Inodes, err = parseDfNumber(row[i])
+				inodesKnown = true
+			case lh == "iused":
+				v.InodesUsed, err = parseDfNumber(row[i])
+			case lh == "ifree":
+				v.InodesFree, err = parseDfNumber(row[i])
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid df line: %s", scanner.Text()))
+			continue
+		}
+
+		if hasInodes && !inodesKnown {
+			// BSD's df -i only prints the used and free inodes
+			v.Inodes = v.InodesUsed + v.InodesFree
+		}
+		if hasBlocks {
+			v.BlockSize = blockSize
+		} else {
+			// without block counts, hide filesystems without inodes, just like
+			// filesystems without blocks
+			v.Blocks = v.Inodes
+			v.BlockSize = 1
+		}
+		v.DeviceType = guessDeviceType(v.Device, v.Fstype)
+
+		m = append(m, v)
+	}
+
+	return m, warnings, scanner.Err()
+}
+
+// mergeDfMounts adds the mounts of another df output to m. Mounts that are
+// already known get completed with the block or inode counts they lack.
+func mergeDfMounts(m, other []Mount) []Mount {
+	for _, o := range other {
+		found := false
+		for i, v := range m {
+			if v.Host != o.Host || v.Device != o.Device || v.Mountpoint != o.Mountpoint {
+				continue
+			}
+			found = true
+
+			if v.Fstype == "" {
+				m[i].Fstype = o.Fstype
+				m[i].DeviceType = o.DeviceType
+			}
+			if v.Total == 0 && o.Total > 0 {
+				m[i].Total, m[i].Used, m[i].Free = o.Total, o.Used, o.Free
+				m[i].Blocks, m[i].BlockSize = o.Blocks, o.BlockSize
+			}
+			if v.Inodes == 0 && o.Inodes > 0 {
+				m[i].Inodes, m[i].InodesUsed, m[i].InodesFree = o.Inodes, o.InodesUsed, o.InodesFree
+			}
+			break
+		}
+
+		if !found {
+			m = append(m, o)
+		}
+	}
+
+	return m
+}
+
+// readDfMounts reads the mounts from the df outputs supplied, merging block
+// and inode counts of the same mounts. Outputs not supplied as HOST=FILE are
+// taken from the same host, named after the first of these outputs. If paths
+// are supplied, only the mounts containing these paths are returned.
+func readDfMounts(inputs []string, paths []string) ([]Mount, []string, error) {
+	var m []Mount
+	var warnings []string
+	var defaultHost string
+	for _, input := range inputs {
+		host, name := parseInput(input)
+
+		var data []byte
+		var err error
+		if name == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading df output: %s", err)
+		}
+
+		dm, w, err := parseDf(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing df output %s: %s", name, err)
+		}
+		for _, warning := range w {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", inputHost(host, name), warning))
+		}
+		if host == "" && defaultHost == "" {
+			defaultHost = inputHost(host, name)
+		}
+		for i := range dm {
+			dm[i].Host = host
+		}
+
+		m = mergeDfMounts(m, dm)
+	}
+	for i := range m {
+		if m[i].Host == "" {
+			m[i].Host = defaultHost
+		}
+	}
+
+	if len(paths) > 0 {
+		m, err := mountsForPaths(m, paths, findSnapshotMounts)
+		return m, warnings, err
+	}
+	return m, warnings, nil
+}
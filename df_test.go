@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDf(t *testing.T) {
+	var tt = []struct {
+		name     string
+		input    string
+		expected []Mount
+		warnings []string
+	}{
+		{
+			name: "blocks with types",
+			input: `Filesystem     Type     1024-blocks     Used Available Capacity Mounted on
+/dev/sda1      ext4        10000000  4000000   5500000      43% /
+tmpfs          tmpfs          16384        0     16384       0% /dev/shm
+server:/export nfs4        20000000 10000000  10000000      50% /mnt/my share
+`,
+			expected: []Mount{
+				{
+					Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/", Fstype: "ext4",
+					Total: 10000000 * 1024, Used: 4000000 * 1024, Free: 5500000 * 1024,
+					Blocks: 10000000, BlockSize: 1024,
+				},
+				{
+					Device: "tmpfs", DeviceType: specialDevice, Mountpoint: "/dev/shm", Fstype: "tmpfs",
+					Total: 16384 * 1024, Free: 16384 * 1024,
+					Blocks: 16384, BlockSize: 1024,
+				},
+				{
+					Device: "server:/export", DeviceType: networkDevice, Mountpoint: "/mnt/my share", Fstype: "nfs4",
+					Total: 20000000 * 1024, Used: 10000000 * 1024, Free: 10000000 * 1024,
+					Blocks: 20000000, BlockSize: 1024,
+				},
+			},
+		},
+		{
+			name: "inodes",
+			input: `Filesystem      Inodes  IUsed   IFree IUse% Mounted on
+/dev/sda1       655360  12345  643015    2% /
+/dev/sdb1            -      -       -     - /boot/efi
+`,
+			expected: []Mount{
+				{
+					Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/",
+					Inodes: 655360, InodesUsed: 12345, InodesFree: 643015,
+					Blocks: 655360, BlockSize: 1,
+				},
+				{
+					Device: "/dev/sdb1", DeviceType: localDevice, Mountpoint: "/boot/efi",
+					BlockSize: 1,
+				},
+			},
+		},
+		{
+			name: "bsd",
+			input: `Filesystem 512-blocks Used Available Capacity iused ifree %iused Mounted on
+/dev/disk1s1 1000 600 400 60% 10 90 10% /
+`,
+			expected: []Mount{
+				{
+					Device: "/dev/disk1s1", DeviceType: localDevice, Mountpoint: "/",
+					Total: 1000 * 512, Used: 600 * 512, Free: 400 * 512,
+					Inodes: 100, InodesUsed: 10, InodesFree: 90,
+					Blocks: 1000, BlockSize: 512,
+				},
+			},
+		},
+		{
+			name: "spaces",
+			input: `Filesystem    512-blocks      Used Available Capacity  Mounted on
+map auto_home          0         0         0   100%    /System/Volumes/Data/home
+/dev/disk3s5        1000       600       400    60%    /Volumes/My Disk 2
+broken line
+`,
+			expected: []Mount{
+				{
+					Device: "map auto_home", DeviceType: localDevice, Mountpoint: "/System/Volumes/Data/home",
+					BlockSize: 512,
+				},
+				{
+					Device: "/dev/disk3s5", DeviceType: localDevice, Mountpoint: "/Volumes/My Disk 2",
+					Total: 1000 * 512, Used: 600 * 512, Free: 400 * 512,
+					Blocks: 1000, BlockSize: 512,
+				},
+			},
+			warnings: []string{"invalid df line: broken line"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, warnings, err := parseDf(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, m)
+			}
+			if !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Errorf("expected warnings %q, got %q", tc.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestReadDfMounts(t *testing.T) {
+	dir := t.TempDir()
+	blocks := filepath.Join(dir, "blocks.txt")
+	inodes := filepath.Join(dir, "inodes.txt")
+	if err := os.WriteFile(blocks, []byte(`Filesystem     Type     1024-blocks     Used Available Capacity Mounted on
+/dev/sda1      ext4        10000000  4000000   5500000      43% /
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(inodes, []byte(`Filesystem      Inodes  IUsed   IFree IUse% Mounted on
+/dev/sda1       655360  12345  643015    2% /
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, _, err := readDfMounts([]string{blocks, inodes}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Mount{
+		{
+			Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/", Fstype: "ext4",
+			Total: 10000000 * 1024, Used: 4000000 * 1024, Free: 5500000 * 1024,
+			Inodes: 655360, InodesUsed: 12345, InodesFree: 643015,
+			Blocks: 10000000, BlockSize: 1024,
+			Host: "blocks",
+		},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/muesli/termenv"
+)
+
+// Kinds of changes to a mount between two snapshots.
+const (
+	mountAdded   = "added"
+	mountRemoved = "removed"
+	mountChanged = "changed"
+)
+
+// MountChange describes how a mount changed between two snapshots. Before is
+// the zero value for added mounts, After for removed mounts.
+type MountChange struct {
+	Kind          string
+	Before, After Mount
+}
+
+// Details returns the changes to the device, filesystem type and mount
+// options of the mount.
+func (c MountChange) Details() []string {
+	if c.Kind != mountChanged {
+		return nil
+	}
+
+	var details []string
+	if c.Before.Device != c.After.Device {
+		details = append(details, fmt.Sprintf("device %s → %s", c.Before.Device, c.After.Device))
+	}
+	if c.Before.Fstype != c.After.Fstype {
+		details = append(details, fmt.Sprintf("type %s → %s", c.Before.Fstype, c.After.Fstype))
+	}
+
+	before := parseCommaSeparatedValues(c.Before.Opts)
+	after := parseCommaSeparatedValues(c.After.Opts)
+	var opts []string
+	for _, o := range strings.Split(c.After.Opts, ",") {
+		if _, ok := before[strings.ToLower(o)]; !ok && o != "" {
+			opts = append(opts, "+"+o)
+		}
+	}
+	for _, o := range strings.Split(c.Before.Opts, ",") {
+		if _, ok := after[strings.ToLower(o)]; !ok && o != "" {
+			opts = append(opts, "-"+o)
+		}
+	}
+	if len(opts) > 0 {
+		details = append(details, "opts "+strings.Join(opts, " "))
+	}
+
+	return details
+}
+
+// signedDelta returns the difference between two unsigned values.
+func signedDelta(before, after uint64) int64 {
+	return int64(after) - int64(before) //nolint:gosec // sizes fit into int64
+}
+
+// diffMounts compares two lists of mounts by their mount points and returns
+// the mounts that appeared, disappeared or changed. Mounts stacked on the same
+// mount point are compared in order.
+func diffMounts(before, after []Mount) []MountChange {
+	key := func(m []Mount) map[string]Mount {
+		keyed := make(map[string]Mount, len(m))
+		seen := map[string]int{}
+		for _, v := range m {
+			keyed[v.Mountpoint+"\x00"+strconv.Itoa(seen[v.Mountpoint])] = v
+			seen[v.Mountpoint]++
+		}
+		return keyed
+	}
+	b, a := key(before), key(after)
+
+	var changes []MountChange
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok {
+			changes = append(changes, MountChange{Kind: mountRemoved, Before: bv})
+			continue
+		}
+
+		c := MountChange{Kind: mountChanged, Before: bv, After: av}
+		if len(c.Details()) > 0 || bv.Total != av.Total || bv.Used != av.Used || bv.InodesUsed != av.InodesUsed {
+			changes = append(changes, c)
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			changes = append(changes, MountChange{Kind: mountAdded, After: av})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].mountpoint() < changes[j].mountpoint()
+	})
+	return changes
+}
+
+// mountpoint returns the mount point of the changed mount.
+func (c MountChange) mountpoint() string {
+	if c.Kind == mountRemoved {
+		return c.Before.Mountpoint
+	}
+	return c.After.Mountpoint
+}
+
+// inodeDeltaString prettifies a signed inode count.
+func inodeDeltaString(delta int64) string {
+	if delta > 0 {
+		return "+" + strconv.FormatInt(delta, 10)
+	}
+	return strconv.FormatInt(delta, 10)
+}
+
+// changeTransformer colors the kind of a change.
+func changeTransformer(val interface{}) string {
+	s := termenv.String(val.(string))
+	switch val.(string) {
+	case mountAdded:
+		s = s.Foreground(theme.colorGreen)
+	case mountRemoved:
+		s = s.Foreground(theme.colorRed)
+	default:
+		s = s.Foreground(theme.colorYellow)
+	}
+
+	return s.String()
+}
+
+// printDiff prints a table of the changed mounts.
+func printDiff(changes []MountChange, opts TableOptions) {
+	if len(changes) == 0 {
+		fmt.Fprintln(opts.Output, "no changes")
+		return
+	}
+
+	tab := table.NewWriter()
+	initializeTable(tab, opts)
+	tab.AppendHeader(table.Row{"Change", "Mounted on", "Size", "Used", "IUsed", "Details"})
+	for _, c := range changes {
+		tab.AppendRow(table.Row{
+			c.Kind,
+			termenv.String(c.mountpoint()).Foreground(theme.colorBlue),
+			deltaString(signedDelta(c.Before.Total, c.After.Total), true),
+			deltaString(signedDelta(c.Before.Used, c.After.Used), true),
+			inodeDeltaString(signedDelta(c.Before.InodesUsed, c.After.InodesUsed)),
+			strings.Join(c.Details(), ", "),
+		})
+	}
+	tab.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Transformer: changeTransformer},
+		{Number: 3, Transformer: deltaTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 4, Transformer: deltaTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 5, Transformer: deltaTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight},
+	})
+
+	suffix := "device"
+	if tab.Length() > 1 {
+		suffix = "devices"
+	}
+	tab.SetTitle("%d changed %s", tab.Length(), suffix)
+	tab.Render()
+}
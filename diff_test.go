@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffMounts(t *testing.T) {
+	root := Mount{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Opts: "rw,relatime", Total: 100, Used: 50}
+
+	var tt = []struct {
+		name    string
+		before  []Mount
+		after   []Mount
+		kinds   []string
+		details [][]string
+	}{
+		{
+			name:   "unchanged",
+			before: []Mount{root},
+			after:  []Mount{root},
+		},
+		{
+			name:   "added and removed",
+			before: []Mount{root, {Device: "/dev/sdb1", Mountpoint: "/mnt/old"}},
+			after:  []Mount{root, {Device: "/dev/sdc1", Mountpoint: "/mnt/new"}},
+			kinds:  []string{mountAdded, mountRemoved},
+			details: [][]string{
+				nil,
+				nil,
+			},
+		},
+		{
+			name:    "usage",
+			before:  []Mount{root},
+			after:   []Mount{{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Opts: "rw,relatime", Total: 100, Used: 60}},
+			kinds:   []string{mountChanged},
+			details: [][]string{nil},
+		},
+		{
+			name:    "device, type and options",
+			before:  []Mount{root},
+			after:   []Mount{{Device: "/dev/sdb1", Mountpoint: "/", Fstype: "xfs", Opts: "ro,relatime,noexec", Total: 100, Used: 50}},
+			kinds:   []string{mountChanged},
+			details: [][]string{{"device /dev/sda1 → /dev/sdb1", "type ext4 → xfs", "opts +ro +noexec -rw"}},
+		},
+		{
+			name: "stacked mounts",
+			before: []Mount{
+				root,
+				{Device: "tmpfs", Mountpoint: "/tmp", Fstype: "tmpfs", Opts: "rw"},
+			},
+			after: []Mount{
+				root,
+				{Device: "tmpfs", Mountpoint: "/tmp", Fstype: "tmpfs", Opts: "rw"},
+				{Device: "tmpfs", Mountpoint: "/tmp", Fstype: "tmpfs", Opts: "rw,noexec"},
+			},
+			kinds:   []string{mountAdded},
+			details: [][]string{nil},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var kinds []string
+			var details [][]string
+			for _, c := range diffMounts(tc.before, tc.after) {
+				kinds = append(kinds, c.Kind)
+				details = append(details, c.Details())
+			}
+			if !reflect.DeepEqual(kinds, tc.kinds) {
+				t.Errorf("expected changes %v, got %v", tc.kinds, kinds)
+			}
+			if !reflect.DeepEqual(details, tc.details) {
+				t.Errorf("expected details %q, got %q", tc.details, details)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+// Time spans within which a filesystem running full is highlighted.
+const (
+	etaCritical = 24 * time.Hour
+	etaWarning  = 7 * 24 * time.Hour
+)
+
+// maxETA caps projections, so they fit into a time.Duration.
+const maxETA = 100 * 365 * 24 * time.Hour
+
+// noETA is the table value of mounts without a projection.
+const noETA time.Duration = -1
+
+// growthRate estimates how fast a usage value of mount v grows across the
+// samples, in units per second. It fits a line through all samples, so a
+// single spike doesn't dominate the projection.
+func growthRate(v Mount, samples []Sample, value func(Usage) uint64) (float64, bool) {
+	var n, sumX, sumY, sumXY, sumXX float64
+	var t0 time.Time
+	var y0 uint64
+	for _, s := range samples {
+		u, ok := s.Usages[v.Mountpoint]
+		if !ok {
+			continue
+		}
+		if n == 0 {
+			t0 = s.Time
+			y0 = value(u)
+		}
+
+		x := s.Time.Sub(t0).Seconds()
+		y := float64(value(u)) - float64(y0)
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	d := n*sumXX - sumX*sumX
+	if n < 2 || d == 0 {
+		return 0, false
+	}
+
+	return (n*sumXY - sumX*sumY) / d, true
+}
+
+// timeToFull estimates when mount v runs out of blocks or inodes, whichever
+// comes first, based on its growth across the samples.
+func timeToFull(v Mount, samples []Sample) (time.Duration, bool) {
+	projections := []struct {
+		free  uint64
+		total uint64
+		value func(Usage) uint64
+	}{
+		{v.Free, v.Total, func(u Usage) uint64 { return u.Used }},
+		{v.InodesFree, v.Inodes, func(u Usage) uint64 { return u.InodesUsed }},
+	}
+
+	var eta time.Duration
+	found := false
+	for _, p := range projections {
+		if p.total == 0 {
+			continue
+		}
+		rate, ok := growthRate(v, samples, p.value)
+		if !ok || rate <= 0 {
+			continue
+		}
+
+		secs := math.Min(float64(p.free)/rate, maxETA.Seconds())
+		d := time.Duration(secs * float64(time.Second))
+		if !found || d < eta {
+			eta = d
+			found = true
+		}
+	}
+
+	return eta, found
+}
+
+// etaString prettifies a time-to-full projection.
+func etaString(d time.Duration, ok bool) string {
+	switch {
+	case !ok:
+		return ""
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	default:
+		return ">1y"
+	}
+}
+
+// etaTransformer makes a time-to-full projection human-readable and applies a
+// color coding.
+func etaTransformer(val interface{}) string {
+	d := val.(time.Duration)
+	if d == noETA {
+		return ""
+	}
+
+	s := termenv.String(etaString(d, true))
+	switch {
+	case d < etaCritical:
+		s = s.Foreground(theme.colorRed)
+	case d < etaWarning:
+		s = s.Foreground(theme.colorYellow)
+	default:
+		s = s.Foreground(theme.colorGreen)
+	}
+
+	return s.String()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeToFull(t *testing.T) {
+	t0 := time.Unix(1600000000, 0)
+	sample := func(offset time.Duration, used, inodesUsed uint64) Sample {
+		return Sample{
+			Time: t0.Add(offset),
+			Usages: map[string]Usage{
+				"/": {Total: 1000, Used: used, Inodes: 100, InodesUsed: inodesUsed},
+			},
+		}
+	}
+	mount := Mount{Mountpoint: "/", Total: 1000, Free: 600, Inodes: 100, InodesFree: 90}
+
+	var tt = []struct {
+		name     string
+		samples  []Sample
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			name:    "single sample",
+			samples: []Sample{sample(0, 400, 10)},
+		},
+		{
+			name:    "no growth",
+			samples: []Sample{sample(0, 400, 10), sample(time.Minute, 400, 10)},
+		},
+		{
+			name:    "shrinking",
+			samples: []Sample{sample(0, 500, 10), sample(time.Minute, 400, 10)},
+		},
+		{
+			name:     "blocks",
+			samples:  []Sample{sample(0, 340, 10), sample(time.Minute, 370, 10), sample(2*time.Minute, 400, 10)},
+			expected: 20 * time.Minute,
+			ok:       true,
+		},
+		{
+			name:     "inodes first",
+			samples:  []Sample{sample(0, 340, 0), sample(time.Minute, 370, 10)},
+			expected: 9 * time.Minute,
+			ok:       true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			eta, ok := timeToFull(mount, tc.samples)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if eta != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, eta)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -23,10 +25,16 @@ func findMounts(mounts []Mount, path string) ([]Mount, error) {
 		return nil, err
 	}
 
+	return closestMounts(mounts, path), nil
+}
+
+// closestMounts returns the mounts closest to the absolute path, without
+// accessing the filesystem.
+func closestMounts(mounts []Mount, path string) []Mount {
 	var m []Mount
 	for _, v := range mounts {
 		if path == v.Device {
-			return []Mount{v}, nil
+			return []Mount{v}
 		}
 
 		if strings.HasPrefix(path, v.Mountpoint) {
@@ -47,7 +55,170 @@ func findMounts(mounts []Mount, path string) ([]Mount, error) {
 		}
 	}
 
-	return m, nil
+	return m
+}
+
+// rootPath returns path p relative to root, and whether p is beneath root at
+// all.
+func rootPath(p, root string) (string, bool) {
+	switch {
+	case p == root:
+		return "/", true
+	case strings.HasPrefix(p, root+"/"):
+		return strings.TrimPrefix(p, root), true
+	}
+	return p, false
+}
+
+// rootMount returns the mount with its mount point and bind source relative to
+// root, and whether it is mounted beneath root at all. Bind sources outside of
+// root are left untouched.
+func rootMount(v Mount, root string) (Mount, bool) {
+	if root == "" || root == "/" {
+		return v, true
+	}
+
+	var ok bool
+	if v.Mountpoint, ok = rootPath(v.Mountpoint, root); !ok {
+		return v, false
+	}
+	v.BindSource, _ = rootPath(v.BindSource, root)
+	return v, true
+}
+
+// rootMounts returns the mounts beneath root, with their mount points relative
+// to it. If root isn't a mount point itself, the mount containing it becomes
+// the root mount.
+func rootMounts(mounts []Mount, root string) []Mount {
+	var m []Mount
+	hasRoot := false
+	for _, v := range mounts {
+		if v, ok := rootMount(v, root); ok {
+			hasRoot = hasRoot || v.Mountpoint == "/"
+			m = append(m, v)
+		}
+	}
+	if hasRoot || root == "" || root == "/" {
+		return m
+	}
+
+	// the last of stacked mounts is the visible one
+	var parent *Mount
+	for i, v := range mounts {
+		if isSubpath(root, v.Mountpoint) && (parent == nil || len(v.Mountpoint) >= len(parent.Mountpoint)) {
+			parent = &mounts[i]
+		}
+	}
+	if parent == nil {
+		return m
+	}
+	v := *parent
+	v.Mountpoint = "/"
+	v.BindSource, _ = rootPath(v.BindSource, root)
+
+	return append([]Mount{v}, m...)
+}
+
+// resolveInRoot resolves the symlinks of path p as if root were the root
+// directory, and returns the resolved path relative to root. Relative paths
+// are relative to root, too.
+func resolveInRoot(root, p string) (string, error) {
+	resolved := "/"
+	rest := strings.Split(p, "/")
+	for links := 0; len(rest) > 0; {
+		name := rest[0]
+		rest = rest[1:]
+
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, name)
+		fi, err := os.Lstat(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		// like the kernel, give up on symlink loops
+		links++
+		if links > 40 {
+			return "", fmt.Errorf("too many levels of symbolic links: %s", p)
+		}
+		target, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+
+	return resolved, nil
+}
+
+// findRootMounts returns the mounts containing path p beneath the alternate
+// root. The mount points of mounts have to be relative to the root already.
+func findRootMounts(mounts []Mount, p string) ([]Mount, error) {
+	p, err := resolveInRoot(*rootDir, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return closestMounts(mounts, p), nil
+}
+
+// bindSource returns the source of bind mount v, or an empty string if v isn't
+// a bind mount. A mount is a bind mount if one of the mounts mounted before it
+// shows the same filesystem at or above its root, e.g. /srv/www bind mounted
+// from /dev/sda1 at /. If the source isn't mounted, it is written like findmnt
+// does, e.g. /dev/sda1[/srv/www], as long as the filesystem is mounted
+// elsewhere too or lives on a block device. Network filesystems are commonly
+// mounted with a subdirectory as their root, without being bind mounts.
+func bindSource(v Mount, before []Mount) string {
+	if v.Devno == "" || v.Root == "" {
+		return ""
+	}
+
+	var source *Mount
+	for i, o := range before {
+		if o.Devno != v.Devno || !isSubpath(v.Root, o.Root) {
+			continue
+		}
+		if source == nil || len(o.Root) < len(source.Root) {
+			source = &before[i]
+		}
+	}
+	if source != nil {
+		return path.Join(source.Mountpoint, strings.TrimPrefix(v.Root, source.Root))
+	}
+
+	// subvolumes are mounted with the subvolume as root
+	if v.Root == "/" || v.Fstype == "btrfs" || v.Fstype == "bcachefs" {
+		return ""
+	}
+	for _, o := range before {
+		if o.Devno == v.Devno {
+			return v.Device + "[" + v.Root + "]"
+		}
+	}
+	if strings.HasPrefix(v.Device, "/dev/") {
+		return v.Device + "[" + v.Root + "]"
+	}
+	return ""
+}
+
+// isBindMount returns true if v is a bind mount.
+func isBindMount(v Mount) bool {
+	return v.BindSource != "" || strings.Contains(v.Opts, "bind")
 }
 
 func deviceType(m Mount) string {
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBindSource(t *testing.T) {
+	before := []Mount{
+		{Device: "/dev/sda1", Devno: "8:1", Root: "/", Mountpoint: "/", Fstype: "ext4"},
+		{Device: "/dev/sdb1", Devno: "8:17", Root: "/@", Mountpoint: "/data", Fstype: "btrfs"},
+		{Device: "srv:/export/media", Devno: "0:98", Root: "/export/media", Mountpoint: "/media", Fstype: "nfs4"},
+	}
+
+	var tt = []struct {
+		name     string
+		mount    Mount
+		expected string
+	}{
+		{
+			name:     "directory",
+			mount:    Mount{Device: "/dev/sda1", Devno: "8:1", Root: "/srv/www", Mountpoint: "/var/www", Fstype: "ext4"},
+			expected: "/srv/www",
+		},
+		{
+			name:     "duplicate",
+			mount:    Mount{Device: "/dev/sda1", Devno: "8:1", Root: "/", Mountpoint: "/mnt", Fstype: "ext4"},
+			expected: "/",
+		},
+		{
+			name:     "unmounted source",
+			mount:    Mount{Device: "/dev/sdc1", Devno: "8:33", Root: "/volumes/db", Mountpoint: "/db", Fstype: "xfs"},
+			expected: "/dev/sdc1[/volumes/db]",
+		},
+		{
+			name:  "btrfs subvolume",
+			mount: Mount{Device: "/dev/sdb1", Devno: "8:17", Root: "/@home", Mountpoint: "/home", Fstype: "btrfs"},
+		},
+		{
+			name:  "network filesystem",
+			mount: Mount{Device: "srv:/export/home", Devno: "0:99", Root: "/export/home", Mountpoint: "/home", Fstype: "nfs4"},
+		},
+		{
+			name:     "network filesystem mounted elsewhere",
+			mount:    Mount{Device: "srv:/export/music", Devno: "0:98", Root: "/export/music", Mountpoint: "/music", Fstype: "nfs4"},
+			expected: "srv:/export/music[/export/music]",
+		},
+		{
+			name:  "bcachefs subvolume",
+			mount: Mount{Device: "/dev/sde1", Devno: "0:50", Root: "/home", Mountpoint: "/home", Fstype: "bcachefs"},
+		},
+		{
+			name:  "other filesystem",
+			mount: Mount{Device: "/dev/sdd1", Devno: "8:49", Root: "/", Mountpoint: "/backup", Fstype: "ext4"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if s := bindSource(tc.mount, before); s != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, s)
+			}
+		})
+	}
+}
+
+func TestRootMounts(t *testing.T) {
+	m := []Mount{
+		{Device: "/dev/sda1", Mountpoint: "/"},
+		{Device: "/dev/sdb1", Mountpoint: "/srv/chroot"},
+		{Device: "/dev/sdb1", Mountpoint: "/srv/chroot/var/www", BindSource: "/srv/chroot/srv/www"},
+		{Device: "/dev/sda1", Mountpoint: "/srv/chroot/etc/hosts", BindSource: "/etc/hosts"},
+		{Device: "/dev/sdc1", Mountpoint: "/srv/chroot/db", BindSource: "/dev/sdc1[/volumes/db]"},
+		{Device: "/dev/sdb1", Mountpoint: "/srv/chroot/mnt", BindSource: "/srv/chroot"},
+	}
+
+	expected := []Mount{
+		{Device: "/dev/sdb1", Mountpoint: "/"},
+		{Device: "/dev/sdb1", Mountpoint: "/var/www", BindSource: "/srv/www"},
+		{Device: "/dev/sda1", Mountpoint: "/etc/hosts", BindSource: "/etc/hosts"},
+		{Device: "/dev/sdc1", Mountpoint: "/db", BindSource: "/dev/sdc1[/volumes/db]"},
+		{Device: "/dev/sdb1", Mountpoint: "/mnt", BindSource: "/"},
+	}
+
+	if rm := rootMounts(m, "/srv/chroot"); !reflect.DeepEqual(rm, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rm)
+	}
+
+	// the mount containing a root that isn't a mount point becomes its root
+	expected = []Mount{
+		{Device: "/dev/sdb1", Mountpoint: "/"},
+		{Device: "/dev/sdb1", Mountpoint: "/www", BindSource: "/srv/chroot/srv/www"},
+	}
+	if rm := rootMounts(m, "/srv/chroot/var"); !reflect.DeepEqual(rm, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rm)
+	}
+}
+
+func TestResolveInRoot(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"var/data", "etc"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"etc/absolute": "/var/data",
+		"etc/relative": "../var",
+		"etc/loop":     "/etc/loop",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tt = []struct {
+		path     string
+		expected string
+		err      bool
+	}{
+		{path: "/", expected: "/"},
+		{path: "/var/data", expected: "/var/data"},
+		{path: "var/../etc", expected: "/etc"},
+		{path: "/../../var", expected: "/var"},
+		{path: "/etc/absolute", expected: "/var/data"},
+		{path: "/etc/relative/data", expected: "/var/data"},
+		{path: "/etc/loop", err: true},
+		{path: "/missing", err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.path, func(t *testing.T) {
+			p, err := resolveInRoot(root, tc.path)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if p != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, p)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
@@ -25,8 +29,10 @@ type FilterOptions struct {
 	OnlyMountPoints   map[string]struct{}
 }
 
-// renderTables renders all tables.
-func renderTables(m []Mount, filters FilterOptions, opts TableOptions) {
+// filterMounts applies the filters to the mounts and returns the remaining
+// mounts, grouped by their device type. Groups that should not be shown are
+// omitted from the result.
+func filterMounts(m []Mount, filters FilterOptions) map[string][]Mount {
 	deviceMounts := make(map[string][]Mount)
 	hasOnlyDevices := len(filters.OnlyDevices) != 0
 
@@ -64,7 +70,7 @@ func renderTables(m []Mount, filters FilterOptions, opts TableOptions) {
 		}
 
 		// skip bind-mounts
-		if strings.Contains(v.Opts, "bind") {
+		if isBindMount(v) {
 			if (hasOnlyDevices && !onlyBinds) || (hideBinds && !*all) {
 				continue
 			}
@@ -101,14 +107,11 @@ func renderTables(m []Mount, filters FilterOptions, opts TableOptions) {
 			}
 		}
 
-		t := deviceType(v)
-		deviceMounts[t] = append(deviceMounts[t], v)
+		deviceMounts[v.DeviceType] = append(deviceMounts[v.DeviceType], v)
 	}
 
-	// print tables
+	// drop hidden groups
 	for _, devType := range groups {
-		mounts := deviceMounts[devType]
-
 		shouldPrint := *all
 		if !shouldPrint {
 			switch devType {
@@ -123,8 +126,105 @@ func renderTables(m []Mount, filters FilterOptions, opts TableOptions) {
 			}
 		}
 
-		if shouldPrint {
-			printTable(devType, mounts, opts)
+		if !shouldPrint {
+			delete(deviceMounts, devType)
+		}
+	}
+
+	return deviceMounts
+}
+
+// flattenMounts returns the grouped mounts as a single list, in the order of
+// the groups.
+func flattenMounts(deviceMounts map[string][]Mount) []Mount {
+	var m []Mount
+	for _, devType := range groups {
+		m = append(m, deviceMounts[devType]...)
+	}
+
+	return m
+}
+
+// localHostname returns the hostname of the local system.
+var localHostname = sync.OnceValue(func() string {
+	hostname, _ := os.Hostname()
+	return hostname
+})
+
+// mountHost returns the host mount v was read on. Mounts of the local system
+// aren't tagged with a host.
+func mountHost(v Mount) string {
+	if v.Host != "" {
+		return v.Host
+	}
+	return localHostname()
+}
+
+// groupByHost regroups the grouped mounts by their hosts. It returns the
+// mounts keyed by host and the sorted list of hosts.
+func groupByHost(deviceMounts map[string][]Mount) (map[string][]Mount, []string) {
+	hostMounts := make(map[string][]Mount)
+	var hosts []string
+	for _, devType := range groups {
+		for _, v := range deviceMounts[devType] {
+			host := mountHost(v)
+			if _, ok := hostMounts[host]; !ok {
+				hosts = append(hosts, host)
+			}
+			hostMounts[host] = append(hostMounts[host], v)
+		}
+	}
+	sort.Strings(hosts)
+
+	return hostMounts, hosts
+}
+
+// tableTitle returns the title of a table containing n mounts of a group.
+func tableTitle(group string, n int, groupBy string) string {
+	suffix := "device"
+	if n > 1 {
+		suffix = "devices"
+	}
+
+	switch {
+	case groupBy == "host":
+		return fmt.Sprintf("%s: %d %s", group, n, suffix)
+	case group == "":
+		return fmt.Sprintf("%d %s", n, suffix)
+	}
+	return fmt.Sprintf("%d %s %s", n, group, suffix)
+}
+
+// renderTables renders all tables.
+func renderTables(m []Mount, filters FilterOptions, opts TableOptions) {
+	if opts.Tree != "" {
+		renderTree(m, filters, opts)
+		return
+	}
+
+	deviceMounts := filterMounts(m, filters)
+
+	titles := groups
+	if opts.GroupBy == "host" {
+		deviceMounts, titles = groupByHost(deviceMounts)
+	}
+
+	if opts.Format == "html" {
+		fmt.Println(htmlStyle)
+	}
+
+	// print tables
+	for _, title := range titles {
+		mounts, ok := deviceMounts[title]
+		if !ok {
+			continue
+		}
+
+		switch opts.Format {
+		case "markdown", "html":
+			printMarkupTable(title, mounts, opts)
+		default:
+			printTable(title, mounts, opts)
 		}
 	}
 }
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/muesli/termenv"
+)
+
+// compactAfter is the age after which recorded samples are thinned out to one
+// sample per hour.
+const compactAfter = 7 * 24 * time.Hour
+
+// staleLockAge is the age after which the lock of the history file is
+// considered to be left behind by a crashed run.
+const staleLockAge = time.Minute
+
+// historyPath returns the path of the history file. Unless supplied, it's
+// stored in $XDG_STATE_HOME/duf.
+func historyPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error locating history file: %s", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, "duf", "history.jsonl"), nil
+}
+
+// parseSpan parses a time span. In addition to the units supported by
+// time.ParseDuration, days (d) and weeks (w) are accepted, e.g. 30d.
+func parseSpan(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid time span: %s", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid time span: %s", s)
+	}
+	return d, nil
+}
+
+// readHistory reads the samples recorded after since from the history file.
+// A missing history file contains no samples.
+func readHistory(path string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %s", err)
+	}
+	defer f.Close() //nolint:errcheck // ignore error
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var s Sample
+		// skip lines that were cut off, e.g. by a full disk
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		if s.Time.Before(since) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %s", err)
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+	return samples, nil
+}
+
+// compactHistory drops samples older than the retention period and thins out
+// samples older than compactAfter to one sample per hour. The samples must be
+// sorted by time.
+func compactHistory(samples []Sample, now time.Time, retention time.Duration) []Sample {
+	var compacted []Sample
+	var lastHour time.Time
+	for _, s := range samples {
+		age := now.Sub(s.Time)
+		if age > retention {
+			continue
+		}
+		if age > compactAfter {
+			hour := s.Time.Truncate(time.Hour)
+			if hour.Equal(lastHour) {
+				continue
+			}
+			lastHour = hour
+		}
+
+		compacted = append(compacted, s)
+	}
+
+	return compacted
+}
+
+// recordSample appends a sample to the history file and compacts it.
+func recordSample(path string, sample Sample, retention time.Duration) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating history directory: %s", err)
+	}
+
+	b, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("error writing history: %s", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error writing history: %s", err)
+	}
+	// write the sample at once, so samples recorded concurrently don't
+	// interleave
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing history: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing history: %s", err)
+	}
+
+	return compactHistoryFile(path, sample.Time, retention)
+}
+
+// compactHistoryFile compacts the history file, if it contains samples to be
+// dropped or thinned out. Only one process compacts the file at a time, guarded
+// by a lock file. If the lock is taken, or samples are recorded while the file
+// is being compacted, compacting is left to the next run.
+func compactHistoryFile(path string, now time.Time, retention time.Duration) error {
+	lock := path + ".lock"
+	l, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if os.IsExist(err) {
+		// remove locks left behind by runs that died while compacting
+		if fi, err := os.Stat(lock); err == nil && time.Since(fi.ModTime()) > staleLockAge {
+			_ = os.Remove(lock)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error locking history: %s", err)
+	}
+	_ = l.Close()
+	defer os.Remove(lock) //nolint:errcheck // ignore error
+
+	before, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("error reading history: %s", err)
+	}
+	samples, err := readHistory(path, time.Time{})
+	if err != nil {
+		return err
+	}
+	compacted := compactHistory(samples, now, retention)
+	if len(compacted) == len(samples) {
+		return nil
+	}
+
+	// write to a temporary file in the same directory first, so an interrupted
+	// run doesn't lose the history.
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing history: %s", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // ignore error
+
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	for _, s := range compacted {
+		if err := enc.Encode(s); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("error writing history: %s", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing history: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing history: %s", err)
+	}
+
+	// replacing the file would lose the samples recorded in the meantime
+	after, err := os.Stat(path)
+	if err != nil || after.Size() != before.Size() {
+		return nil
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error writing history: %s", err)
+	}
+	return nil
+}
+
+// needsHistory returns true if any of the columns cols is derived from the
+// recorded usage history.
+func needsHistory(cols []int) bool {
+	for _, c := range cols {
+		switch columns[c-1].ID {
+		case "eta", "trend":
+			return true
+		}
+	}
+
+	return false
+}
+
+// dailyUsage holds the usage statistics of a mount on a single day.
+type dailyUsage struct {
+	Day           string
+	Min, Max, Sum float64
+	Count         int
+}
+
+// historyUsage aggregates the recorded usage of mount v per day, in the local
+// time zone.
+func historyUsage(v Mount, samples []Sample) []dailyUsage {
+	var days []dailyUsage
+	for _, s := range samples {
+		u, ok := s.Usages[v.Mountpoint]
+		if !ok || u.Total == 0 {
+			continue
+		}
+
+		day := s.Time.Local().Format("2006-01-02")
+		usage := usageRatio(u.Used, u.Total)
+		if len(days) == 0 || days[len(days)-1].Day != day {
+			days = append(days, dailyUsage{Day: day, Min: usage, Max: usage})
+		}
+
+		d := &days[len(days)-1]
+		d.Min = min(d.Min, usage)
+		d.Max = max(d.Max, usage)
+		d.Sum += usage
+		d.Count++
+	}
+
+	return days
+}
+
+// usageTransformer prints a usage ratio as a percentage and applies a color
+// coding.
+func usageTransformer(val interface{}) string {
+	usage := val.(float64)
+
+	s := termenv.String(fmt.Sprintf("%.1f%%", usage*100))
+	switch usageLevel(usage, *usageThreshold) {
+	case levelCritical:
+		s = s.Foreground(theme.colorRed)
+	case levelWarning:
+		s = s.Foreground(theme.colorYellow)
+	default:
+		s = s.Foreground(theme.colorGreen)
+	}
+
+	return s.String()
+}
+
+// printHistory prints a table of the daily minimum, average and maximum usage
+// for each of the grouped mounts.
+func printHistory(samples []Sample, deviceMounts map[string][]Mount, opts TableOptions) error {
+	printed := false
+	vis := map[string]struct{}{}
+	for _, devType := range groups {
+		m := deviceMounts[devType]
+		sortMounts(m, opts.SortBy, nil)
+
+		for _, v := range m {
+			if _, ok := vis[v.Mountpoint]; ok {
+				continue
+			}
+			vis[v.Mountpoint] = struct{}{}
+
+			days := historyUsage(v, samples)
+			if len(days) == 0 {
+				continue
+			}
+
+			tab := table.NewWriter()
+			initializeTable(tab, opts)
+			tab.AppendHeader(table.Row{"Day", "Min", "Avg", "Max"})
+			for _, d := range days {
+				tab.AppendRow(table.Row{d.Day, d.Min, d.Sum / float64(d.Count), d.Max})
+			}
+			tab.SetColumnConfigs([]table.ColumnConfig{
+				{Number: 2, Transformer: usageTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight},
+				{Number: 3, Transformer: usageTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight},
+				{Number: 4, Transformer: usageTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight},
+			})
+
+			suffix := "day"
+			if len(days) > 1 {
+				suffix = "days"
+			}
+			tab.SetTitle("%s (%d %s)", termenv.String(v.Mountpoint).Foreground(theme.colorBlue), len(days), suffix)
+			tab.Render()
+			printed = true
+		}
+	}
+
+	if !printed {
+		return fmt.Errorf("no history recorded for the selected mounts")
+	}
+	return nil
+}
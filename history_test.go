@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompactHistory(t *testing.T) {
+	now := time.Date(2020, 9, 30, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	var samples []Sample
+	for _, offset := range []time.Duration{
+		40 * 24 * time.Hour,              // beyond retention
+		10*24*time.Hour + 30*time.Minute, // compacted, first of its hour
+		10*24*time.Hour + 20*time.Minute, // compacted, same hour
+		10*24*time.Hour + 10*time.Minute, // compacted, same hour
+		9*24*time.Hour + 50*time.Minute,  // compacted, next hour
+		2*time.Hour + 10*time.Minute,     // recent
+		2*time.Hour + 5*time.Minute,      // recent, same hour
+		0,
+	} {
+		samples = append(samples, Sample{Time: now.Add(-offset)})
+	}
+
+	expected := []time.Duration{
+		10*24*time.Hour + 30*time.Minute,
+		9*24*time.Hour + 50*time.Minute,
+		2*time.Hour + 10*time.Minute,
+		2*time.Hour + 5*time.Minute,
+		0,
+	}
+
+	compacted := compactHistory(samples, now, retention)
+	if len(compacted) != len(expected) {
+		t.Fatalf("expected %d samples, got %d", len(expected), len(compacted))
+	}
+	for i, offset := range expected {
+		if !compacted[i].Time.Equal(now.Add(-offset)) {
+			t.Errorf("expected sample %d at %s, got %s", i, now.Add(-offset), compacted[i].Time)
+		}
+	}
+}
+
+func TestRecordSample(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Date(2020, 9, 30, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	// samples are appended without rewriting the file
+	for _, offset := range []time.Duration{2 * time.Hour, time.Hour} {
+		if err := recordSample(path, Sample{Time: now.Add(-offset)}, retention); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples, err := readHistory(path, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+
+	// expired samples are dropped once a newer sample is recorded
+	if err := recordSample(path, Sample{Time: now.Add(retention)}, retention); err != nil {
+		t.Fatal(err)
+	}
+	samples, err = readHistory(path, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(samples))
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("expected lock to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// influxMeasurement is the measurement name used in InfluxDB line protocol.
+const influxMeasurement = "duf"
+
+var influxTagEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+
+// influxLine formats mount v of the given device group as a single line of
+// InfluxDB line protocol.
+func influxLine(v Mount, devType string, t time.Time) string {
+	var b strings.Builder
+	b.WriteString(influxMeasurement)
+
+	// tags with empty values are invalid and must be omitted
+	for _, tag := range [][2]string{
+		{"mountpoint", v.Mountpoint},
+		{"device", v.Device},
+		{"fstype", v.Fstype},
+		{"group", devType},
+	} {
+		if tag[1] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ",%s=%s", tag[0], influxTagEscaper.Replace(tag[1]))
+	}
+
+	fmt.Fprintf(&b, " total=%di,free=%di,used=%di,usage=%g,inodes=%di,inodes_free=%di,inodes_used=%di,inodes_usage=%g,blocks=%di,block_size=%di %d",
+		v.Total, v.Free, v.Used, usageRatio(v.Used, v.Total),
+		v.Inodes, v.InodesFree, v.InodesUsed, usageRatio(v.InodesUsed, v.Inodes),
+		v.Blocks, v.BlockSize,
+		t.UnixNano())
+
+	return b.String()
+}
+
+// renderInflux prints all grouped mounts in InfluxDB line protocol.
+func renderInflux(w io.Writer, deviceMounts map[string][]Mount) error {
+	bw := bufio.NewWriter(w)
+
+	t := time.Now()
+	for _, devType := range groups {
+		for _, v := range deviceMounts[devType] {
+			fmt.Fprintln(bw, influxLine(v, devType, t))
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("error writing the influx output: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInfluxLine(t *testing.T) {
+	ts := time.Unix(1600000000, 42)
+
+	var tt = []struct {
+		mount    Mount
+		devType  string
+		expected string
+	}{
+		{
+			mount: Mount{
+				Device:     "/dev/sda1",
+				Mountpoint: "/",
+				Fstype:     "ext4",
+				Total:      100,
+				Free:       25,
+				Used:       50,
+				Inodes:     10,
+				InodesFree: 9,
+				InodesUsed: 1,
+				Blocks:     25,
+				BlockSize:  4,
+			},
+			devType:  localDevice,
+			expected: "duf,mountpoint=/,device=/dev/sda1,fstype=ext4,group=local total=100i,free=25i,used=50i,usage=0.5,inodes=10i,inodes_free=9i,inodes_used=1i,inodes_usage=0.1,blocks=25i,block_size=4i 1600000000000000042",
+		},
+		{
+			mount: Mount{
+				Device:     `C:\Program Files`,
+				Mountpoint: "/mnt/a b,c=d",
+				Fstype:     "9p",
+			},
+			devType:  localDevice,
+			expected: `duf,mountpoint=/mnt/a\ b\,c\=d,device=C:\\Program\ Files,fstype=9p,group=local total=0i,free=0i,used=0i,usage=0,inodes=0i,inodes_free=0i,inodes_used=0i,inodes_usage=0,blocks=0i,block_size=0i 1600000000000000042`,
+		},
+		{
+			mount: Mount{
+				Device:     `C:\`,
+				Mountpoint: "/mnt/c",
+				Fstype:     "9p",
+			},
+			devType:  localDevice,
+			expected: `duf,mountpoint=/mnt/c,device=C:\\,fstype=9p,group=local total=0i,free=0i,used=0i,usage=0,inodes=0i,inodes_free=0i,inodes_used=0i,inodes_usage=0,blocks=0i,block_size=0i 1600000000000000042`,
+		},
+		{
+			mount: Mount{
+				Mountpoint: "/proc",
+				Fstype:     "proc",
+			},
+			devType:  specialDevice,
+			expected: "duf,mountpoint=/proc,fstype=proc,group=special total=0i,free=0i,used=0i,usage=0,inodes=0i,inodes_free=0i,inodes_used=0i,inodes_usage=0,blocks=0i,block_size=0i 1600000000000000042",
+		},
+	}
+
+	for _, tc := range tt {
+		actual := influxLine(tc.mount, tc.devType, ts)
+		if actual != tc.expected {
+			t.Errorf("\ninfluxLine(%+v) == \n%s, \nexpected \n%s", tc.mount, actual, tc.expected)
+		}
+	}
+}
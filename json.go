@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// JSON output schema versions. Version 1 is a plain list of mounts, version 2
+// wraps the mounts in an envelope with metadata about the host and duf run.
+const (
+	jsonSchemaV1 = 1
+	jsonSchemaV2 = 2
+)
+
+// JSONEnvelope wraps the mounts with metadata in schema version 2.
+type JSONEnvelope struct {
+	Schema    int         `json:"schema"`
+	Hostname  string      `json:"hostname"`
+	Timestamp time.Time   `json:"timestamp"`
+	Version   string      `json:"version"`
+	Warnings  []string    `json:"warnings"`
+	Mounts    interface{} `json:"mounts"`
+}
+
+// jsonFields returns the JSON fields of mount v for the columns with the
+// given indices.
+func jsonFields(v Mount, cols []int) map[string]interface{} {
+	obj := make(map[string]interface{}, len(cols))
+	for _, c := range cols {
+		switch columns[c-1].ID {
+		case "mountpoint":
+			obj["mount_point"] = v.Mountpoint
+		case "size":
+			obj["total"] = v.Total
+		case "used":
+			obj["used"] = v.Used
+		case "avail":
+			obj["free"] = v.Free
+		case "usage":
+			obj["usage"] = usageRatio(v.Used, v.Total)
+		case "inodes":
+			obj["inodes"] = v.Inodes
+		case "inodes_used":
+			obj["inodes_used"] = v.InodesUsed
+		case "inodes_avail":
+			obj["inodes_free"] = v.InodesFree
+		case "inodes_usage":
+			obj["inodes_usage"] = usageRatio(v.InodesUsed, v.Inodes)
+		case "type":
+			obj["fs_type"] = v.Fstype
+		case "filesystem":
+			obj["device"] = v.Device
+		case "host":
+			obj["host"] = mountHost(v)
+		case "id":
+			obj["mount_id"] = v.MountID
+		case "parent":
+			obj["parent_id"] = v.ParentID
+		case "devno":
+			obj["devno"] = v.Devno
+		case "root":
+			obj["root"] = v.Root
+		case "propagation":
+			obj["propagation"] = v.Propagation
+		case "bind_source":
+			obj["bind_source"] = v.BindSource
+		case "also_mounted_at":
+			if v.AlsoMountedAt == nil {
+				obj["also_mounted_at"] = []string{}
+			} else {
+				obj["also_mounted_at"] = v.AlsoMountedAt
+			}
+		}
+	}
+
+	return obj
+}
+
+// renderJSON encodes the JSON output and prints it. If cols is not empty, only
+// the fields of these columns are included.
+func renderJSON(w io.Writer, deviceMounts map[string][]Mount, cols []int, sortBy int, schema int, warnings []string) error {
+	m := []Mount{}
+	for _, devType := range groups {
+		m = append(m, deviceMounts[devType]...)
+	}
+	sortMounts(m, sortBy, nil)
+
+	var mounts interface{} = m
+	if len(cols) > 0 {
+		objs := make([]map[string]interface{}, 0, len(m))
+		for _, v := range m {
+			objs = append(objs, jsonFields(v, cols))
+		}
+		mounts = objs
+	}
+
+	if schema == jsonSchemaV2 {
+		hostname, _ := os.Hostname()
+		if warnings == nil {
+			warnings = []string{}
+		}
+
+		mounts = JSONEnvelope{
+			Schema:    jsonSchemaV2,
+			Hostname:  hostname,
+			Timestamp: time.Now(),
+			Version:   Version,
+			Warnings:  warnings,
+			Mounts:    mounts,
+		}
+	}
+
+	output, err := json.MarshalIndent(mounts, "", " ")
+	if err != nil {
+		return fmt.Errorf("error formatting the json output: %s", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(output))
+	return err
+}
+
+// streamJSON prints every mount matching the filters as a compact JSON object
+// on its own line, as soon as it has been read. If paths are supplied, the
+// whole mount table has to be read first to find the mounts containing them.
+func streamJSON(w io.Writer, paths []string, filters FilterOptions, cols []int) ([]string, error) {
+	enc := json.NewEncoder(w)
+	emit := func(v Mount) error {
+		if len(filterMounts([]Mount{v}, filters)) == 0 {
+			return nil
+		}
+
+		var obj interface{} = v
+		if len(cols) > 0 {
+			obj = jsonFields(v, cols)
+		}
+		if err := enc.Encode(obj); err != nil {
+			return fmt.Errorf("error writing the json output: %s", err)
+		}
+		return nil
+	}
+
+	// mounts can only be deduplicated, and the mount containing an alternate
+	// root be found, once the whole mount table is known
+	if len(paths) == 0 && !*dedupe && *rootDir == "" {
+		return walkMounts(emit)
+	}
+
+	m, warnings, err := readMounts(paths, filters)
+	if err != nil {
+		return warnings, err
+	}
+	for _, v := range m {
+		if err := emit(v); err != nil {
+			return warnings, err
+		}
+	}
+
+	return warnings, nil
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	wildcard "github.com/IGLOU-EU/go-wildcard"
@@ -44,26 +46,124 @@ var (
 	width    = flag.Uint("width", 0, "max output width")
 	themeOpt = flag.String("theme", defaultThemeName(), "color themes: dark, light, ansi")
 	styleOpt = flag.String("style", defaultStyleName(), "style: unicode, ascii")
+	format   = flag.String("format", "table", "output format: table, csv, tsv, markdown, html, ndjson, influx, waybar, i3bar")
+	watchOpt = flag.Duration("watch", 0, "refresh the output in the given interval, e.g. 2s")
+	dedupe   = flag.Bool("dedupe", false, "show mounts of the same filesystem as a single device")
+	tree     = flag.String("tree", "", "show the mounts as a tree, collapsing nested pseudo filesystems: collapse, expand")
+	groupBy  = flag.String("group-by", "device", "group tables by: device, host")
+	diff     = flag.Bool("diff", false, "compare a snapshot to another snapshot, or to the current mounts")
+	inputs   = flag.StringSlice("input", nil, "read the mounts from the JSON output of duf, findmnt or lsblk instead of the system, - for stdin, optionally as HOST=FILE")
+	dfInputs = flag.StringSlice("from-df", nil, "read the mounts from the output of df -P, -PT or -Pi instead of the system, - for stdin, optionally as HOST=FILE")
+
+	rootDir   = flag.String("root", "", "only list the mounts beneath a directory, relative to it, e.g. in a rescue system")
+	pid       = flag.Int("pid", 0, "read the mounts a process sees, e.g. in a container (Linux only)")
+	mountinfo = flag.String("mountinfo", "", "read the mount table from a mountinfo file instead of /proc/self/mountinfo (Linux only)")
+
+	templateOpt  = flag.String("template", "", "output format defined by a Go template, e.g. '{{range .}}{{.Mountpoint}} {{.Free | human}}{{\"\\n\"}}{{end}}'")
+	templateFile = flag.String("template-file", "", "output format defined by a Go template read from a file")
 
 	availThreshold = flag.String("avail-threshold", "10G,1G", "specifies the coloring threshold (yellow, red) of the avail column, must be integer with optional SI prefixes")
 	usageThreshold = flag.String("usage-threshold", "0.5,0.9", "specifies the coloring threshold (yellow, red) of the usage bars as a floating point number from 0 to 1")
 
+	inodesUsageThreshold = flag.String("inodes-usage-threshold", "0.5,0.9", "specifies the coloring threshold (yellow, red) of the inode usage bars as a floating point number from 0 to 1")
+
 	_          = flag.BoolP("human-readable", "h", false, "ignored, just for df compatibility")
+	raw        = flag.Bool("raw", false, "print sizes in bytes and usages as ratios in csv and tsv output")
 	inodes     = flag.Bool("inodes", false, "list inode information instead of block usage")
-	jsonOutput = flag.Bool("json", false, "output all devices in JSON format")
+	jsonOutput = flag.Bool("json", false, "output devices in JSON format")
+	jsonSchema = flag.Int("json-schema", jsonSchemaV1, "JSON output schema: 1 (list of devices), 2 (devices with metadata)")
 	warns      = flag.Bool("warnings", false, "output all warnings to STDERR")
 	version    = flag.Bool("version", false, "display version")
+
+	metricsAddr = flag.String("serve-metrics", "", "serve Prometheus metrics on the given address, e.g. :9929")
+	textfile    = flag.String("textfile", "", "write Prometheus metrics to a file, e.g. for node_exporter's textfile collector")
+	check       = flag.Bool("check", false, "check the thresholds and exit with a Nagios-compatible status code")
+
+	record           = flag.Bool("record", false, "append a sample of the mounts to the history file")
+	history          = flag.Bool("history", false, "print the daily usage of the mounts from the history file")
+	historyFile      = flag.String("history-file", "", "history file (default $XDG_STATE_HOME/duf/history.jsonl)")
+	historyRetention = flag.String("history-retention", "90d", "discard recorded samples older than the given time span")
+	since            = flag.String("since", "7d", "time span of the history to use, e.g. 30d")
 )
 
-// renderJSON encodes the JSON output and prints it.
-func renderJSON(m []Mount) error {
-	output, err := json.MarshalIndent(m, "", " ")
+// mountsForPaths returns the mounts containing the supplied paths, as located
+// by find.
+func mountsForPaths(m []Mount, paths []string, find func([]Mount, string) ([]Mount, error)) ([]Mount, error) {
+	var mounts []Mount
+	vis := map[string]struct{}{}
+
+	for _, v := range paths {
+		fm, err := find(m, v)
+		if err != nil {
+			return nil, err
+		}
+		// de-duplicate
+		for _, v := range fm {
+			if _, ok := vis[v.Mountpoint]; !ok {
+				mounts = append(mounts, v)
+				vis[v.Mountpoint] = struct{}{}
+			}
+		}
+	}
+
+	return mounts, nil
+}
+
+// readMounts reads the mount table. If paths are supplied, only the mounts
+// containing these paths are returned. The filters are only needed to dedupe
+// the mounts.
+func readMounts(paths []string, filters FilterOptions) ([]Mount, []string, error) {
+	m, warnings, err := mounts()
 	if err != nil {
-		return fmt.Errorf("error formatting the json output: %s", err)
+		return nil, nil, err
 	}
 
-	fmt.Println(string(output))
-	return nil
+	m = rootMounts(m, *rootDir)
+
+	if len(paths) > 0 {
+		// paths are relative to the root of the process whose mounts are read,
+		// so they can't be resolved in duf's file system
+		find := findMounts
+		switch {
+		case *pid > 0 || *mountinfo != "":
+			find = findSnapshotMounts
+		case *rootDir != "":
+			find = findRootMounts
+		}
+
+		// paths are relative to the alternate root, too
+		if *rootDir != "" {
+			rooted := make([]string, len(paths))
+			for i, p := range paths {
+				rooted[i] = path.Join("/", p)
+			}
+			paths = rooted
+		}
+
+		m, err = mountsForPaths(m, paths, find)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if *dedupe {
+		m = dedupeVisibleMounts(m, filters)
+	}
+
+	return m, warnings, nil
+}
+
+// terminalWidth returns the width of the terminal, or 80 if stdout is not a
+// terminal.
+func terminalWidth() uint {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		w, _, err := term.GetSize(int(os.Stdout.Fd()))
+		if err == nil && w > 0 {
+			return uint(w)
+		}
+	}
+
+	return 80
 }
 
 // parseColumns parses the supplied output flag into a slice of column indices.
@@ -115,18 +215,21 @@ func parseCommaSeparatedValues(values string) map[string]struct{} {
 	return m
 }
 
+// isGroup returns true if s is the name of a device group.
+func isGroup(s string) bool {
+	for _, g := range groups {
+		if g == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 // validateGroups validates the parsed group maps.
 func validateGroups(m map[string]struct{}) error {
 	for k := range m {
-		found := false
-		for _, g := range groups {
-			if g == k {
-				found = true
-				break
-			}
-		}
-
-		if !found {
+		if !isGroup(k) {
 			return fmt.Errorf("unknown device group: %s", k)
 		}
 	}
@@ -145,10 +248,11 @@ func findInKey(str string, km map[string]struct{}) bool {
 	return false
 }
 
-func printVersion() {
+// readBuildInfo completes the version information from the build info of the
+// binary and returns its build time and whether it was built from a modified
+// source tree.
+func readBuildInfo() (buildTime time.Time, modified bool) {
 	info, ok := debug.ReadBuildInfo()
-	var buildTime time.Time
-	var modified bool
 	if ok {
 		if len(Version) == 0 {
 			vs := strings.Split(info.Main.Version, "-")
@@ -178,6 +282,12 @@ func printVersion() {
 		Version = "(built from source)"
 	}
 
+	return buildTime, modified
+}
+
+func printVersion() {
+	buildTime, modified := readBuildInfo()
+
 	fmt.Printf("duf %s", Version)
 	if len(CommitSHA) > 0 {
 		if modified {
@@ -195,6 +305,7 @@ func printVersion() {
 func main() {
 	// hide -h from help, it's just for df compatibility
 	_ = flag.CommandLine.MarkHidden("human-readable")
+	flag.Lookup("tree").NoOptDefVal = treeCollapse
 	flag.Parse()
 
 	if *version {
@@ -202,49 +313,125 @@ func main() {
 		os.Exit(0)
 	}
 
-	// read mount table
-	m, warnings, err := mounts()
+	// validate theme
+	var err error
+	theme, err = loadTheme(*themeOpt)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-
-	// print JSON
-	if *jsonOutput {
-		if err = renderJSON(m); err != nil {
+	if env == termenv.ANSI {
+		// enforce ANSI theme for limited color support
+		theme, err = loadTheme("ansi")
+		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
-		return
 	}
 
-	// validate theme
-	theme, err = loadTheme(*themeOpt)
+	// validate style
+	style, err := parseStyle(*styleOpt)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	if env == termenv.ANSI {
-		// enforce ANSI theme for limited color support
-		theme, err = loadTheme("ansi")
+
+	// validate output format
+	switch *format {
+	case "table", "csv", "tsv", "markdown", "html", "ndjson", "influx", "waybar", "i3bar":
+	default:
+		fmt.Fprintln(os.Stderr, fmt.Errorf("unknown output format: %s", *format))
+		os.Exit(1)
+	}
+
+	// validate template
+	var tmpl *template.Template
+	if *templateOpt != "" && *templateFile != "" {
+		fmt.Fprintln(os.Stderr, "only one of --template and --template-file can be used")
+		os.Exit(1)
+	}
+	if *templateOpt != "" || *templateFile != "" {
+		tmpl, err = parseTemplate(*templateOpt, *templateFile)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
 	}
 
-	// validate style
-	style, err := parseStyle(*styleOpt)
+	// validate watch mode
+	if *watchOpt < 0 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid watch interval: %s", *watchOpt))
+		os.Exit(1)
+	}
+	if *watchOpt > 0 && ((*format != "table" && *format != "ndjson" && *format != "waybar" && *format != "i3bar") ||
+		*jsonOutput || tmpl != nil || *check || *textfile != "" || *metricsAddr != "") {
+		fmt.Fprintln(os.Stderr, "--watch is only supported for table, ndjson, waybar and i3bar output")
+		os.Exit(1)
+	}
+
+	if *watchOpt > 0 && (*record || *history) {
+		fmt.Fprintln(os.Stderr, "--watch can't be combined with --record or --history")
+		os.Exit(1)
+	}
+
+	// validate snapshot input
+	hasInputs := len(*inputs) > 0 || len(*dfInputs) > 0
+	if hasInputs && (*watchOpt > 0 || *record || *metricsAddr != "" || *format == "ndjson") {
+		fmt.Fprintln(os.Stderr, "--input and --from-df can't be combined with --watch, --record, --serve-metrics or ndjson output")
+		os.Exit(1)
+	}
+
+	// read the mount table of another process, or beneath an alternate root
+	if hasInputs && (*pid > 0 || *mountinfo != "") {
+		fmt.Fprintln(os.Stderr, "--pid and --mountinfo can't be combined with --input or --from-df")
+		os.Exit(1)
+	}
+	if hasInputs && *rootDir != "" {
+		fmt.Fprintln(os.Stderr, "--root can't be combined with --input or --from-df")
+		os.Exit(1)
+	}
+	if *rootDir != "" {
+		*rootDir, err = filepath.Abs(*rootDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, fmt.Errorf("invalid root: %s", err))
+			os.Exit(1)
+		}
+	}
+	if *pid < 0 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid pid: %d", *pid))
+		os.Exit(1)
+	}
+	if err := setMountNamespace(*pid, *mountinfo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// validate history time spans
+	sinceSpan, err := parseSpan(*since)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	retention, err := parseSpan(*historyRetention)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
+	// validate JSON schema
+	if *jsonSchema != jsonSchemaV1 && *jsonSchema != jsonSchemaV2 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("unknown JSON schema: %d", *jsonSchema))
+		os.Exit(1)
+	}
+
 	// validate output columns
 	columns, err := parseColumns(*output)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	// JSON only restricts its fields if columns were explicitly requested
+	jsonColumns := columns
 	if len(columns) == 0 {
 		// no columns supplied, use defaults
 		if *inodes {
@@ -252,10 +439,35 @@ func main() {
 		} else {
 			columns = []int{1, 2, 3, 4, 5, 10, 11}
 		}
+		if *dedupe {
+			// list the mount points that were collapsed
+			col, _ := stringToColumn("also_mounted_at")
+			columns = append(columns, col)
+		}
+	}
+
+	// validate grouping
+	if *groupBy != "device" && *groupBy != "host" {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("unknown grouping: %s", *groupBy))
+		os.Exit(1)
+	}
+
+	// validate tree view
+	if *tree != "" && *tree != treeCollapse && *tree != treeExpand {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("unknown tree mode: %s", *tree))
+		os.Exit(1)
+	}
+	if *tree != "" && *dedupe {
+		fmt.Fprintln(os.Stderr, "--tree can't be combined with --dedupe")
+		os.Exit(1)
+	}
+	if *tree != "" && (*format != "table" && *format != "markdown" && *format != "html" || *jsonOutput || tmpl != nil) {
+		fmt.Fprintln(os.Stderr, "--tree is only supported for table, markdown and html output")
+		os.Exit(1)
 	}
 
 	// validate sort column
-	sortCol, err := stringToSortIndex(*sortBy)
+	sortCol, err := stringToColumn(*sortBy)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -281,30 +493,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	// validate arguments
-	if len(flag.Args()) > 0 {
-		var mounts []Mount
-		vis := map[string]struct{}{}
-
-		for _, v := range flag.Args() {
-			var fm []Mount
-			fm, err = findMounts(m, v)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-			// de-duplicate
-			for _, v := range fm {
-				if _, ok := vis[v.Mountpoint]; !ok {
-					mounts = append(mounts, v)
-					vis[v.Mountpoint] = struct{}{}
-				}
-			}
-		}
-
-		m = mounts
-	}
-
 	// validate availability thresholds
 	availbilityThresholds := strings.Split(*availThreshold, ",")
 	if len(availbilityThresholds) != 2 {
@@ -333,6 +521,113 @@ func main() {
 		}
 	}
 
+	// validate inode usage thresholds
+	inodesUsageThresholds := strings.Split(*inodesUsageThreshold, ",")
+	if len(inodesUsageThresholds) != 2 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("error parsing inodes-usage-threshold: invalid option '%s'", *inodesUsageThreshold))
+		os.Exit(1)
+	}
+	for _, threshold := range inodesUsageThresholds {
+		_, err = strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error parsing inodes-usage-threshold:", err)
+			os.Exit(1)
+		}
+	}
+
+	// serve metrics
+	if *metricsAddr != "" {
+		if err = serveMetrics(*metricsAddr, flag.Args(), filters); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// stream JSON
+	if *format == "ndjson" {
+		for {
+			warnings, err := streamJSON(os.Stdout, flag.Args(), filters, jsonColumns)
+			if *warns {
+				for _, warning := range warnings {
+					fmt.Fprintln(os.Stderr, warning)
+				}
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			if *watchOpt == 0 {
+				return
+			}
+			time.Sleep(*watchOpt)
+		}
+	}
+
+	// compare snapshots
+	if *diff {
+		args := flag.Args()
+		if len(args) == 0 || len(args) > 2 {
+			fmt.Fprintln(os.Stderr, "--diff needs one or two snapshots")
+			os.Exit(1)
+		}
+
+		before, _, err := readSnapshotMounts(args[:1], nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		var after []Mount
+		if len(args) == 2 {
+			after, _, err = readSnapshotMounts(args[1:], nil)
+		} else {
+			after, _, err = readMounts(nil, filters)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if *width == 0 {
+			*width = terminalWidth()
+		}
+		changes := diffMounts(flattenMounts(filterMounts(before, filters)), flattenMounts(filterMounts(after, filters)))
+		printDiff(changes, TableOptions{
+			Style:     style,
+			StyleName: *styleOpt,
+			Output:    os.Stdout,
+		})
+		return
+	}
+
+	// read mount table, or the snapshot supplied instead
+	var m []Mount
+	var warnings []string
+	if hasInputs {
+		m, warnings, err = readSnapshotMounts(*inputs, flag.Args())
+		if err == nil {
+			var dm []Mount
+			var dw []string
+			dm, dw, err = readDfMounts(*dfInputs, flag.Args())
+			m = append(m, dm...)
+			warnings = append(warnings, dw...)
+		}
+		if *dedupe {
+			m = dedupeVisibleMounts(m, filters)
+		}
+	} else {
+		m, warnings, err = readMounts(flag.Args(), filters)
+	}
+	if err != nil {
+		if *check {
+			fmt.Println(checkStatus(checkUnknown, err.Error(), nil))
+			os.Exit(checkUnknown)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	// print out warnings
 	if *warns {
 		for _, warning := range warnings {
@@ -340,23 +635,143 @@ func main() {
 		}
 	}
 
-	// detect terminal width
-	isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
-	if isTerminal && *width == 0 {
-		w, _, err := term.GetSize(int(os.Stdout.Fd()))
-		if err == nil {
-			*width = uint(w)
+	// record a sample of the mounts
+	if *record {
+		path, err := historyPath(*historyFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		sampled := flattenMounts(filterMounts(m, filters))
+		if err = recordSample(path, newSample(time.Now(), sampled), retention); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
 	}
-	if *width == 0 {
-		*width = 80
+
+	// load the recorded samples for the history report and for columns derived
+	// from the usage history
+	var samples []Sample
+	if *history || (needsHistory(columns) && *watchOpt == 0) {
+		path, err := historyPath(*historyFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		samples, err = readHistory(path, time.Now().Add(-sinceSpan))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if !*history {
+			samples = append(samples, newSample(time.Now(), m))
+		}
 	}
 
-	// print tables
-	renderTables(m, filters, TableOptions{
+	// write metrics for node_exporter's textfile collector
+	if *textfile != "" {
+		if err = writeTextfile(*textfile, filterMounts(m, filters)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// check thresholds
+	if *check {
+		code, status := checkMounts(filterMounts(m, filters))
+		fmt.Println(status)
+		os.Exit(code)
+	}
+
+	// print JSON
+	if *jsonOutput {
+		readBuildInfo()
+		if err = renderJSON(os.Stdout, filterMounts(m, filters), jsonColumns, sortCol, *jsonSchema, warnings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// print template
+	if tmpl != nil {
+		if err = renderTemplate(os.Stdout, tmpl, filterMounts(m, filters), sortCol); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// print csv/tsv
+	if *format == "csv" || *format == "tsv" {
+		comma := ','
+		if *format == "tsv" {
+			comma = '\t'
+		}
+		if err = renderCSV(os.Stdout, filterMounts(m, filters), columns, sortCol, samples, comma, !*raw, *styleOpt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// print InfluxDB line protocol
+	if *format == "influx" {
+		if err = renderInflux(os.Stdout, filterMounts(m, filters)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// print status bar
+	if *format == "waybar" || *format == "i3bar" {
+		if err = runStatusBar(os.Stdout, *format, m, flag.Args(), filters, sortCol, *watchOpt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// detect terminal width
+	autoWidth := *width == 0
+	if autoWidth {
+		*width = terminalWidth()
+	}
+
+	opts := TableOptions{
 		Columns:   columns,
 		SortBy:    sortCol,
 		Style:     style,
 		StyleName: *styleOpt,
-	})
+		Format:    *format,
+		GroupBy:   *groupBy,
+		Tree:      *tree,
+		Samples:   samples,
+	}
+
+	// print history report
+	if *history {
+		if err = printHistory(samples, filterMounts(m, filters), opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// watch tables
+	if *watchOpt > 0 {
+		if err = watchTables(*watchOpt, flag.Args(), filters, opts, autoWidth); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// print tables
+	renderTables(m, filters, opts)
 }
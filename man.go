@@ -49,13 +49,13 @@ Sort the output:
 
   $ duf --sort size
 
-Valid keys are: mountpoint, size, used, avail, usage, inodes, inodes_used, inodes_avail, inodes_usage, type, filesystem.
+Valid keys are: mountpoint, size, used, avail, usage, inodes, inodes_used, inodes_avail, inodes_usage, type, filesystem, used_delta, rate, eta, trend, host, id, parent, devno, root, propagation, bind_source, also_mounted_at.
 
 Show or hide specific columns:
 
   $ duf --output mountpoint,size,usage
 
-Valid keys are: mountpoint, size, used, avail, usage, inodes, inodes_used, inodes_avail, inodes_usage, type, filesystem.
+Valid keys are: mountpoint, size, used, avail, usage, inodes, inodes_used, inodes_avail, inodes_usage, type, filesystem, used_delta, rate, eta, trend, host, id, parent, devno, root, propagation, bind_source, also_mounted_at.
 
 List inode information instead of block usage:
 
@@ -65,14 +65,138 @@ If duf doesn't detect your terminal's colors correctly, you can set a theme:
 
   $ duf --theme light
 
+Keep watching your filesystems, refreshing the table in place. The used_delta and rate columns show how much the used space changed since the last refresh:
+
+  $ duf --watch 2s --output mountpoint,size,used,used_delta,rate,avail
+
+The eta column projects when a filesystem runs out of space or inodes, based on its growth during the session:
+
+  $ duf --watch 10s --sort eta --output mountpoint,usage,rate,eta
+
+--watch also works with --format ndjson, printing a new batch of devices on every interval.
+
+To track your filesystems over time, record a sample of them periodically, e.g. from cron. Samples are stored in $XDG_STATE_HOME/duf/history.jsonl (or --history-file), thinned out to one per hour after a week and discarded after --history-retention (90 days by default):
+
+  $ duf --record --only local
+
+Print the daily minimum, average and maximum usage of the recorded mounts:
+
+  $ duf --history /var --since 30d
+
+Outside of watch mode, the eta and trend columns are derived from the samples recorded within the --since time span. trend draws a sparkline of the usage, colored according to the usage thresholds:
+
+  $ duf --output mountpoint,usage,eta,trend --since 2d
+
 duf highlights the availability & usage columns in red, green, or yellow, depending on how much space is still available. You can set your own thresholds:
 
   $ duf --avail-threshold="10G,1G"
   $ duf --usage-threshold="0.5,0.9"
+  $ duf --inodes-usage-threshold="0.5,0.9"
+
+The same thresholds can be used to monitor your filesystems with Nagios or Icinga. duf prints a status line with performance data and exits with 0 (OK), 1 (WARNING), 2 (CRITICAL) or 3 (UNKNOWN):
+
+  $ duf --check --only local
 
 If you prefer your output as JSON:
 
   $ duf --json
+
+The JSON output honors all filters, --sort and, if supplied, --output. Use schema version 2 to wrap the devices in an envelope containing the hostname, a timestamp, duf's version and all warnings:
+
+  $ duf --json --json-schema 2
+
+JSON output can be read back with --input, e.g. to render snapshots collected from other machines. All filters and display options apply; - reads from stdin:
+
+  $ ssh server duf --json --json-schema 2 | duf --input - --only local
+  $ duf --input snapshot.json --sort usage
+
+Pass several snapshots, separated by commas or by repeating --input, to compare hosts in a single report. The mounts are tagged with the hostname recorded in the snapshot, the file name, or the host supplied as HOST=FILE. Show it in the host column, or print a table per host with --group-by host:
+
+  $ duf --input web1.json,web2.json,db1.json --only-mp /var --sort usage --output mountpoint,usage,host
+  $ duf --input web1=a.json,web2=b.json --group-by host
+
+Systems without duf can still be displayed by duf: --from-df reads the output of POSIX df -P, df -PT or df -Pi. Block and inode counts of the same mounts are merged. Outputs without a HOST= prefix are taken from the same host:
+
+  $ ssh legacy-host df -PT | duf --from-df -
+  $ duf --from-df blocks.txt,inodes.txt --inodes
+  $ duf --from-df web1=web1-blocks.txt,web1=web1-inodes.txt,web2=web2.txt
+
+--input also reads the JSON output of findmnt and lsblk, e.g. from rescue systems or bug reports. Ask them for sizes in bytes:
+
+  $ findmnt --json -b -o TARGET,SOURCE,FSTYPE,OPTIONS,SIZE,USED,AVAIL | duf --input -
+  $ lsblk --json -b -o NAME,PATH,FSTYPE,FSSIZE,FSUSED,FSAVAIL,MOUNTPOINTS > lsblk.json
+  $ duf --input lsblk.json
+
+On Linux, list the mounts another process sees, e.g. a container or a systemd service with PrivateTmp, or read a saved mount table:
+
+  $ duf --pid $(pidof nginx)
+  $ duf --mountinfo mountinfo.txt
+
+In a rescue system, only list the mounts of the system mounted beneath a directory. Mount points and path arguments are relative to it, and symlinks in path arguments are resolved within it:
+
+  $ duf --root /mnt/sysimage
+  $ duf --root /mnt/sysimage /var/log
+
+On Linux, debug shared and slave mounts or bind mount roots with the fields of /proc/self/mountinfo:
+
+  $ duf --all --output mountpoint,id,parent,devno,root,propagation
+
+Show which mount overlays which in a tree, like findmnt. Nested pseudo filesystems are collapsed unless the tree is expanded:
+
+  $ duf --tree
+  $ duf --tree=expand --all
+
+On Linux, bind mounts are detected from the mount table. Show where they are bound from, or hide them:
+
+  $ duf --output mountpoint,size,bind_source
+  $ duf --hide binds
+
+Show filesystems mounted in several places, e.g. by bind mounts, btrfs subvolumes or container volumes, only once. The other mount points are listed in the also_mounted_at column:
+
+  $ duf --dedupe
+
+Compare two snapshots, e.g. taken before and after an upgrade, or a snapshot to the current mounts. duf lists the mounts that appeared, disappeared or changed their device, type, options, size or usage:
+
+  $ duf --diff before.json after.json
+  $ duf --diff before.json
+
+For log shippers and streaming pipelines, newline-delimited JSON prints one compact object per device as soon as it has been read:
+
+  $ duf --format ndjson
+
+To feed Telegraf or InfluxDB, print the devices in line protocol:
+
+  $ duf --format influx
+
+duf can also drive status bars. waybar prints a single custom module (with text, tooltip, class and percentage), i3bar speaks the i3bar protocol. The class and colors follow the color-coding thresholds. With --watch, the status is refreshed in the given interval, e.g. to use duf as i3bar's status_command:
+
+  $ duf --format waybar /home
+  $ duf --format i3bar --only-mp /,/home --watch 10s
+
+To paste the results into a spreadsheet, print the selected columns as CSV or TSV. Sizes are human-readable, unless you pass --raw to print them in bytes:
+
+  $ duf --format csv --output mountpoint,size,usage --sort size
+  $ duf --format tsv --raw
+
+For reports, wiki pages and tickets, the tables can be rendered as Markdown or HTML. Values crossing a threshold are highlighted (bold/italic in Markdown, duf-critical/duf-warning/duf-ok CSS classes in HTML):
+
+  $ duf --format markdown
+  $ duf --format html > report.html
+
+If you need a different shape altogether, supply a Go template, which is executed against the list of selected mounts:
+
+  $ duf --template '{{range .}}{{.Mountpoint}} {{.Free | human}}{{"\n"}}{{end}}'
+  $ duf --template-file report.tmpl
+
+Besides the fields of each mount, templates can use the helper functions human, percent, usage, inodeUsage, availLevel, usageLevel, inodeUsageLevel and deviceType.
+
+duf can also run as a Prometheus exporter, serving the size, usage and inode metrics of all selected mounts. Filters are applied on every scrape:
+
+  $ duf --serve-metrics :9929 --only local,network
+
+To feed node_exporter's textfile collector instead, e.g. from a cron job, write the same metrics to a file:
+
+  $ duf --textfile /var/lib/node_exporter/duf.prom
 `
 
 	manPage := mango.NewManPage(1, "duf", "Disk Usage/Free Utility").
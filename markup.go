@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// htmlStyle defines the CSS classes used for the threshold levels in HTML
+// output.
+const htmlStyle = `<style>
+  .duf-ok { color: #005F00; }
+  .duf-warning { color: #FFAF00; }
+  .duf-critical { color: #D70000; }
+</style>`
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`")
+
+// markupValue returns the value of the column with index col for mount v,
+// formatted for Markdown or HTML output. Values that are subject to a
+// threshold are highlighted according to their level.
+func markupValue(v Mount, col int, format string, samples []Sample, styleName string) string {
+	s := columnValue(v, col, true, samples, styleName)
+	if format == "html" {
+		s = html.EscapeString(s)
+	} else {
+		s = markdownEscaper.Replace(s)
+	}
+
+	var level int
+	switch columns[col-1].ID {
+	case "avail":
+		level = availLevel(v.Free)
+	case "usage":
+		level = usageLevel(usageRatio(v.Used, v.Total), *usageThreshold)
+	case "inodes_usage":
+		level = usageLevel(usageRatio(v.InodesUsed, v.Inodes), *inodesUsageThreshold)
+	default:
+		return s
+	}
+
+	if format == "html" {
+		return fmt.Sprintf(`<span class="duf-%s">%s</span>`, levelNames[level], s)
+	}
+
+	switch level {
+	case levelCritical:
+		return "**" + s + "**"
+	case levelWarning:
+		return "_" + s + "_"
+	default:
+		return s
+	}
+}
+
+// printMarkupTable prints an individual table of mounts in Markdown or HTML
+// format.
+func printMarkupTable(title string, m []Mount, opts TableOptions) {
+	if len(m) == 0 {
+		return
+	}
+
+	tab := table.NewWriter()
+	tab.SetOutputMirror(os.Stdout)
+	tab.Style().HTML.EscapeText = false
+
+	var cols []int
+	for i := range columns {
+		if inColumns(opts.Columns, i+1) {
+			cols = append(cols, i+1)
+		}
+	}
+
+	headers := table.Row{}
+	var cfgs []table.ColumnConfig
+	for i, c := range cols {
+		headers = append(headers, columns[c-1].Name)
+
+		if columns[c-1].Width > 0 {
+			cfgs = append(cfgs, table.ColumnConfig{Number: i + 1, Align: text.AlignRight, AlignHeader: text.AlignRight})
+		}
+	}
+	tab.AppendHeader(headers)
+	tab.SetColumnConfigs(cfgs)
+
+	if opts.Tree == "" {
+		// trees are already sorted
+		sortMounts(m, opts.SortBy, opts.Samples)
+	}
+	for _, v := range m {
+		row := table.Row{}
+		for _, c := range cols {
+			row = append(row, markupValue(v, c, opts.Format, opts.Samples, opts.StyleName))
+		}
+		tab.AppendRow(row)
+	}
+
+	tab.SetTitle("%s", tableTitle(title, tab.Length(), opts.GroupBy))
+
+	if opts.Format == "html" {
+		tab.RenderHTML()
+	} else {
+		tab.RenderMarkdown()
+	}
+	fmt.Println()
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metric describes a single Prometheus gauge exported for every mount.
+type metric struct {
+	Name  string
+	Help  string
+	Value func(m Mount) float64
+}
+
+var metrics = []metric{
+	{
+		Name:  "duf_size_bytes",
+		Help:  "Total size of the filesystem in bytes.",
+		Value: func(m Mount) float64 { return float64(m.Total) },
+	},
+	{
+		Name:  "duf_used_bytes",
+		Help:  "Used space of the filesystem in bytes.",
+		Value: func(m Mount) float64 { return float64(m.Used) },
+	},
+	{
+		Name:  "duf_avail_bytes",
+		Help:  "Space available to unprivileged users in bytes.",
+		Value: func(m Mount) float64 { return float64(m.Free) },
+	},
+	{
+		Name:  "duf_inodes",
+		Help:  "Total number of inodes of the filesystem.",
+		Value: func(m Mount) float64 { return float64(m.Inodes) },
+	},
+	{
+		Name:  "duf_inodes_used",
+		Help:  "Number of used inodes of the filesystem.",
+		Value: func(m Mount) float64 { return float64(m.InodesUsed) },
+	},
+	{
+		Name:  "duf_inodes_avail",
+		Help:  "Number of free inodes of the filesystem.",
+		Value: func(m Mount) float64 { return float64(m.InodesFree) },
+	},
+	{
+		Name:  "duf_usage_ratio",
+		Help:  "Ratio of used space to the total size of the filesystem.",
+		Value: func(m Mount) float64 { return usageRatio(m.Used, m.Total) },
+	},
+	{
+		Name:  "duf_inodes_usage_ratio",
+		Help:  "Ratio of used inodes to the total number of inodes.",
+		Value: func(m Mount) float64 { return usageRatio(m.InodesUsed, m.Inodes) },
+	},
+}
+
+// metricsContentType is the content type of the Prometheus text exposition
+// format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// escapeLabelValue escapes a Prometheus label value.
+func escapeLabelValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+// writeMetrics writes the grouped mounts in the Prometheus text exposition
+// format.
+func writeMetrics(w io.Writer, deviceMounts map[string][]Mount) error {
+	bw := bufio.NewWriter(w)
+
+	// Mounts stacked on the same mount point would produce duplicate series,
+	// which Prometheus rejects. Only keep the first one.
+	var labels []string
+	var mounts []Mount
+	vis := map[string]struct{}{}
+	for _, devType := range groups {
+		for _, v := range deviceMounts[devType] {
+			l := fmt.Sprintf(`mountpoint="%s",device="%s",fstype="%s",group="%s"`,
+				escapeLabelValue(v.Mountpoint),
+				escapeLabelValue(v.Device),
+				escapeLabelValue(v.Fstype),
+				devType)
+			if _, ok := vis[l]; ok {
+				continue
+			}
+			vis[l] = struct{}{}
+
+			labels = append(labels, l)
+			mounts = append(mounts, v)
+		}
+	}
+
+	for _, mt := range metrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", mt.Name, mt.Help)
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", mt.Name)
+
+		for i, v := range mounts {
+			fmt.Fprintf(bw, "%s{%s} %s\n", mt.Name, labels[i], strconv.FormatFloat(mt.Value(v), 'f', -1, 64))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// serveMetrics runs an HTTP server on addr, exposing the filtered mounts as
+// Prometheus metrics. The mount table is read anew on every scrape.
+func serveMetrics(addr string, args []string, filters FilterOptions) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		m, _, err := readMounts(args, filters)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", metricsContentType)
+		_ = writeMetrics(w, filterMounts(m, filters))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, "/metrics", http.StatusFound)
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+// writeTextfile atomically writes the grouped mounts as Prometheus metrics to
+// path, e.g. for node_exporter's textfile collector.
+func writeTextfile(path string, deviceMounts map[string][]Mount) error {
+	// write to a temporary file in the same directory first, so the collector
+	// never reads a partially written file.
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating textfile: %s", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // ignore error
+
+	if err := writeMetrics(f, deviceMounts); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing textfile: %s", err)
+	}
+	if err := f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing textfile: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing textfile: %s", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error writing textfile: %s", err)
+	}
+	return nil
+}
@@ -8,21 +8,42 @@ import (
 
 // Mount contains all metadata for a single filesystem mount.
 type Mount struct {
-	Device     string      `json:"device"`
-	DeviceType string      `json:"device_type"`
-	Mountpoint string      `json:"mount_point"`
-	Fstype     string      `json:"fs_type"`
-	Type       string      `json:"type"`
-	Opts       string      `json:"opts"`
-	Total      uint64      `json:"total"`
-	Free       uint64      `json:"free"`
-	Used       uint64      `json:"used"`
-	Inodes     uint64      `json:"inodes"`
-	InodesFree uint64      `json:"inodes_free"`
-	InodesUsed uint64      `json:"inodes_used"`
-	Blocks     uint64      `json:"blocks"`
-	BlockSize  uint64      `json:"block_size"`
-	Metadata   interface{} `json:"-"`
+	Device     string `json:"device"`
+	DeviceType string `json:"device_type"`
+	Mountpoint string `json:"mount_point"`
+	Fstype     string `json:"fs_type"`
+	Type       string `json:"type"`
+	Opts       string `json:"opts"`
+	Total      uint64 `json:"total"`
+	Free       uint64 `json:"free"`
+	Used       uint64 `json:"used"`
+	Inodes     uint64 `json:"inodes"`
+	InodesFree uint64 `json:"inodes_free"`
+	InodesUsed uint64 `json:"inodes_used"`
+	Blocks     uint64 `json:"blocks"`
+	BlockSize  uint64 `json:"block_size"`
+	Host       string `json:"host,omitempty"`
+
+	// Linux only: the fields of the mountinfo line describing the mount.
+	MountID       int      `json:"mount_id,omitempty"`
+	ParentID      int      `json:"parent_id,omitempty"`
+	Devno         string   `json:"devno,omitempty"`
+	Root          string   `json:"root,omitempty"`
+	Propagation   string   `json:"propagation,omitempty"`
+	BindSource    string   `json:"bind_source,omitempty"`
+	AlsoMountedAt []string `json:"also_mounted_at,omitempty"`
+
+	Metadata interface{} `json:"-"`
+	// Label is displayed instead of the mount point, e.g. by the tree view.
+	Label string `json:"-"`
+}
+
+// mountLabel returns the label of mount v, or its mount point if it has none.
+func mountLabel(v Mount) string {
+	if v.Label != "" {
+		return v.Label
+	}
+	return v.Mountpoint
 }
 
 func readLines(filename string) ([]string, error) {
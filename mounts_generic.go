@@ -0,0 +1,33 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "fmt"
+
+// walkMounts reads the mount table and calls fn for every mount. It stops at
+// the first error returned by fn.
+func walkMounts(fn func(Mount) error) ([]string, error) {
+	m, warnings, err := mounts()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range m {
+		if err := fn(v); err != nil {
+			return warnings, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// setMountNamespace reads the mount table of another process or a mountinfo
+// file, which is only supported on Linux.
+func setMountNamespace(pid int, mountinfo string) error {
+	if pid > 0 || mountinfo != "" {
+		return fmt.Errorf("reading the mount table of other processes is only supported on Linux")
+	}
+
+	return nil
+}
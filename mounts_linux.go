@@ -20,13 +20,13 @@ const (
 	// (0) (1) (2)   (3)   (4)      (5)      (6)   (7) (8)    (9)           (10)
 	//
 	// (0) mount ID: unique identifier of the mount (may be reused after umount).
-	//mountinfoMountID = 0
+	mountinfoMountID = 0
 	// (1) parent ID: ID of parent (or of self for the top of the mount tree).
-	//mountinfoParentID = 1
+	mountinfoParentID = 1
 	// (2) major:minor: value of st_dev for files on filesystem.
-	//mountinfoMajorMinor = 2
+	mountinfoMajorMinor = 2
 	// (3) root: root of the mount within the filesystem.
-	//mountinfoRoot = 3
+	mountinfoRoot = 3
 	// (4) mount point: mount point relative to the process's root.
 	mountinfoMountPoint = 4
 	// (5) mount options: per mount options.
@@ -43,22 +43,68 @@ const (
 	mountinfoSuperOptions = 10
 )
 
+var (
+	// mountinfoPath is the mount table read by walkMounts.
+	mountinfoPath = "/proc/self/mountinfo"
+	// mountRoot is the directory the mount points are resolved in to stat
+	// them, e.g. the root directory of another process.
+	mountRoot = ""
+)
+
+// setMountNamespace makes walkMounts read the mount table of the process pid,
+// or the supplied mountinfo file, instead of duf's own. The mount points of
+// another process are resolved in its root directory, so that they can be
+// stated from outside of its mount namespace, which usually requires the same
+// privileges as tracing the process.
+func setMountNamespace(pid int, mountinfo string) error {
+	if pid > 0 {
+		mountinfoPath = fmt.Sprintf("/proc/%d/mountinfo", pid)
+		mountRoot = fmt.Sprintf("/proc/%d/root", pid)
+
+		var stat unix.Statfs_t
+		if err := unix.Statfs(mountRoot, &stat); err != nil {
+			return fmt.Errorf("error accessing the root directory of process %d: %s", pid, err)
+		}
+	}
+	if mountinfo != "" {
+		mountinfoPath = mountinfo
+	}
+
+	return nil
+}
+
 // Stat returns the mountpoint's stat information.
 func (m *Mount) Stat() unix.Statfs_t {
 	return m.Metadata.(unix.Statfs_t)
 }
 
 func mounts() ([]Mount, []string, error) {
+	var ret []Mount
+	warnings, err := walkMounts(func(m Mount) error {
+		ret = append(ret, m)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ret, warnings, nil
+}
+
+// walkMounts reads the mount table and calls fn for every mount as soon as its
+// stat information has been retrieved. It stops at the first error returned
+// by fn.
+func walkMounts(fn func(Mount) error) ([]string, error) {
 	var warnings []string
+	var seen []Mount
 
-	filename := "/proc/self/mountinfo"
+	filename := mountinfoPath
 	lines, err := readLines(filename)
 	if err != nil {
 		// wrapcheck: add context to the error.
-		return nil, nil, fmt.Errorf("reading mountinfo %q: %w", filename, err)
+		return nil, fmt.Errorf("reading mountinfo %q: %w", filename, err)
 	}
 
-	ret := make([]Mount, 0, len(lines))
 	for _, line := range lines {
 		nb, fields := parseMountInfoLine(line)
 		if nb == 0 {
@@ -72,14 +118,21 @@ func mounts() ([]Mount, []string, error) {
 			continue
 		}
 
-		// blockDeviceID := fields[mountinfoMountID]
+		mountID, _ := strconv.Atoi(fields[mountinfoMountID])
+		parentID, _ := strconv.Atoi(fields[mountinfoParentID])
 		mountPoint := fields[mountinfoMountPoint]
 		mountOpts := fields[mountinfoMountOpts]
 		fstype := fields[mountinfoFsType]
 		device := fields[mountinfoMountSource]
 
+		// mounts without optional fields don't propagate events
+		propagation := fields[mountinfoOptionalFields]
+		if propagation == "" {
+			propagation = "private"
+		}
+
 		var stat unix.Statfs_t
-		err := unix.Statfs(mountPoint, &stat)
+		err := unix.Statfs(filepath.Join(mountRoot, mountPoint), &stat)
 		if err != nil {
 			if err != os.ErrPermission {
 				warnings = append(warnings, fmt.Sprintf("%s: %s", mountPoint, err))
@@ -104,6 +157,12 @@ func mounts() ([]Mount, []string, error) {
 			InodesUsed: stat.Files - stat.Ffree,
 			Blocks:     uint64(stat.Blocks), //nolint:unconvert
 			BlockSize:  uint64(stat.Bsize),
+
+			MountID:     mountID,
+			ParentID:    parentID,
+			Devno:       fields[mountinfoMajorMinor],
+			Root:        unescapeFstab(fields[mountinfoRoot]),
+			Propagation: propagation,
 		}
 		d.DeviceType = deviceType(d)
 
@@ -116,10 +175,16 @@ func mounts() ([]Mount, []string, error) {
 			}
 		}
 
-		ret = append(ret, d)
+		// bind mounts are recognized by the mounts read before
+		d.BindSource = bindSource(d, seen)
+		seen = append(seen, d)
+
+		if err := fn(d); err != nil {
+			return warnings, err
+		}
 	}
 
-	return ret, warnings, nil
+	return warnings, nil
 }
 
 // splitMountInfoFields splits a mountinfo line into its fields.
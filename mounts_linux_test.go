@@ -4,6 +4,7 @@
 package main
 
 import (
+	"os"
 	"reflect"
 	"testing"
 )
@@ -187,3 +188,17 @@ func TestGetFields(t *testing.T) {
 		}
 	}
 }
+
+func TestSetMountNamespace(t *testing.T) {
+	defer func(path, root string) {
+		mountinfoPath, mountRoot = path, root
+	}(mountinfoPath, mountRoot)
+
+	if err := setMountNamespace(os.Getpid(), ""); err != nil {
+		t.Errorf("expected no error for our own process, got %s", err)
+	}
+	// pids are never larger than 2^22
+	if err := setMountNamespace(1<<23, ""); err == nil {
+		t.Error("expected an error for a process that doesn't exist")
+	}
+}
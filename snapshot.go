@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Snapshot contains the mounts read from the JSON output of duf.
+type Snapshot struct {
+	Hostname string
+	Warnings []string
+	Mounts   []Mount
+}
+
+// parseSnapshot parses the JSON output of duf, either a list of mounts (schema
+// version 1) or mounts wrapped in an envelope (schema version 2). The JSON
+// output of findmnt and lsblk is accepted as well.
+func parseSnapshot(data []byte) (Snapshot, error) {
+	var s Snapshot
+	var err error
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var envelope struct {
+			Schema       int             `json:"schema"`
+			Hostname     string          `json:"hostname"`
+			Warnings     []string        `json:"warnings"`
+			Mounts       json.RawMessage `json:"mounts"`
+			Filesystems  json.RawMessage `json:"filesystems"`
+			Blockdevices json.RawMessage `json:"blockdevices"`
+		}
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return s, err
+		}
+
+		switch {
+		case envelope.Filesystems != nil:
+			s.Mounts, err = parseFindmnt(envelope.Filesystems)
+			return s, err
+		case envelope.Blockdevices != nil:
+			s.Mounts, err = parseLsblk(envelope.Blockdevices)
+			return s, err
+		}
+		if envelope.Schema != jsonSchemaV2 {
+			return s, fmt.Errorf("unknown JSON schema: %d", envelope.Schema)
+		}
+
+		s.Hostname = envelope.Hostname
+		s.Warnings = envelope.Warnings
+		data = envelope.Mounts
+	}
+
+	if err = json.Unmarshal(data, &s.Mounts); err != nil {
+		return s, err
+	}
+
+	// the device type can't be determined without access to the filesystem
+	for i, v := range s.Mounts {
+		if !isGroup(v.DeviceType) {
+			s.Mounts[i].DeviceType = localDevice
+		}
+	}
+
+	return s, nil
+}
+
+// readSnapshot reads a snapshot from file name, or from stdin if name is "-".
+func readSnapshot(name string) (Snapshot, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("error reading snapshot: %s", err)
+	}
+
+	s, err := parseSnapshot(data)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("error parsing snapshot %s: %s", name, err)
+	}
+	return s, nil
+}
+
+// findSnapshotMounts returns the mounts of a snapshot containing path p. As
+// the snapshot may come from another machine, p isn't resolved locally.
+func findSnapshotMounts(mounts []Mount, p string) ([]Mount, error) {
+	if path.IsAbs(p) {
+		p = path.Clean(p)
+	}
+
+	return closestMounts(mounts, p), nil
+}
+
+// parseInput splits an input of the form HOST=FILE into its host and file
+// name. If no host is supplied, host is empty.
+func parseInput(input string) (host, name string) {
+	if _, err := os.Stat(input); err == nil {
+		return "", input
+	}
+
+	host, name, ok := strings.Cut(input, "=")
+	if !ok {
+		return "", input
+	}
+	return host, name
+}
+
+// inputHost returns the host of an input read from file name. Unless supplied,
+// it's the file's name, without its extension.
+func inputHost(host, name string) string {
+	switch {
+	case host != "":
+		return host
+	case name == "-":
+		return "stdin"
+	default:
+		return strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+}
+
+// readSnapshotMounts reads the mounts of all snapshots and tags them with the
+// host they were taken on. Unless supplied as HOST=FILE, the host is the
+// hostname recorded in the snapshot, or the snapshot's file name. If paths are
+// supplied, only the mounts containing these paths are returned.
+func readSnapshotMounts(inputs []string, paths []string) ([]Mount, []string, error) {
+	var warnings []string
+	var mounts []Mount
+	for _, input := range inputs {
+		host, name := parseInput(input)
+		s, err := readSnapshot(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if host == "" {
+			host = s.Hostname
+		}
+		host = inputHost(host, name)
+
+		sm := s.Mounts
+		if len(paths) > 0 {
+			sm, err = mountsForPaths(sm, paths, findSnapshotMounts)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, v := range sm {
+			v.Host = host
+			mounts = append(mounts, v)
+		}
+
+		for _, w := range s.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", host, w))
+		}
+	}
+
+	return mounts, warnings, nil
+}
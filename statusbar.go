@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// i3barHeader is the header of the i3bar protocol, preceding the endless array
+// of status lines.
+const i3barHeader = `{"version":1}`
+
+// WaybarOutput is the JSON object expected from Waybar's custom modules.
+type WaybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+// I3barBlock is a single block of the i3bar protocol.
+type I3barBlock struct {
+	FullText  string `json:"full_text"`
+	ShortText string `json:"short_text"`
+	Color     string `json:"color"`
+	Urgent    bool   `json:"urgent"`
+	Name      string `json:"name"`
+	Instance  string `json:"instance"`
+}
+
+// i3barColors maps threshold levels to the colors of i3bar blocks.
+var i3barColors = map[int]string{
+	levelOK:       "#A8CC8C",
+	levelWarning:  "#DBAB79",
+	levelCritical: "#E88388",
+}
+
+// mountLevel returns the most severe threshold level of a mount's available
+// space and usage.
+func mountLevel(v Mount) int {
+	if v.Total == 0 {
+		return levelOK
+	}
+
+	return max(availLevel(v.Free), usageLevel(usageRatio(v.Used, v.Total), *usageThreshold))
+}
+
+// statusBarMounts returns the grouped mounts sorted and without duplicate
+// mount points.
+func statusBarMounts(deviceMounts map[string][]Mount, sortBy int) []Mount {
+	var m []Mount
+	vis := map[string]struct{}{}
+	for _, devType := range groups {
+		for _, v := range deviceMounts[devType] {
+			if _, ok := vis[v.Mountpoint]; ok {
+				continue
+			}
+			vis[v.Mountpoint] = struct{}{}
+			m = append(m, v)
+		}
+	}
+	sortMounts(m, sortBy, nil)
+
+	return m
+}
+
+// renderWaybar prints the mounts as a single Waybar module. Its class and
+// percentage reflect the most used mount.
+func renderWaybar(w io.Writer, deviceMounts map[string][]Mount, sortBy int) error {
+	var texts, tooltips []string
+	level := levelOK
+	var usage float64
+
+	for _, v := range statusBarMounts(deviceMounts, sortBy) {
+		texts = append(texts, fmt.Sprintf("%s %s", v.Mountpoint, sizeToString(v.Free)))
+		tooltips = append(tooltips, fmt.Sprintf("%s: %s / %s used (%s), %s avail",
+			v.Mountpoint, sizeToString(v.Used), sizeToString(v.Total),
+			formatUsage(usageRatio(v.Used, v.Total), true), sizeToString(v.Free)))
+
+		level = max(level, mountLevel(v))
+		usage = max(usage, usageRatio(v.Used, v.Total))
+	}
+
+	return json.NewEncoder(w).Encode(WaybarOutput{
+		Text:       strings.Join(texts, " "),
+		Tooltip:    strings.Join(tooltips, "\n"),
+		Class:      levelNames[level],
+		Percentage: int(math.Round(usage * 100)),
+	})
+}
+
+// renderI3bar prints the mounts as a status line of i3bar blocks. Status lines
+// following the first one are separated by a comma.
+func renderI3bar(w io.Writer, deviceMounts map[string][]Mount, sortBy int, first bool) error {
+	blocks := []I3barBlock{}
+	for _, v := range statusBarMounts(deviceMounts, sortBy) {
+		level := mountLevel(v)
+		blocks = append(blocks, I3barBlock{
+			FullText:  fmt.Sprintf("%s %s", v.Mountpoint, sizeToString(v.Free)),
+			ShortText: sizeToString(v.Free),
+			Color:     i3barColors[level],
+			Urgent:    level == levelCritical,
+			Name:      "duf",
+			Instance:  v.Mountpoint,
+		})
+	}
+
+	if !first {
+		if _, err := fmt.Fprint(w, ","); err != nil {
+			return err
+		}
+	}
+	return json.NewEncoder(w).Encode(blocks)
+}
+
+// runStatusBar prints the mounts in the status bar format, waybar or i3bar. If
+// interval is not zero, the mounts are read and printed again in the given
+// interval, until an error occurs. i3bar output follows the i3bar protocol, so
+// duf can be used as its status_command.
+func runStatusBar(w io.Writer, format string, m []Mount, paths []string, filters FilterOptions, sortBy int, interval time.Duration) error {
+	if format == "i3bar" {
+		if _, err := fmt.Fprintf(w, "%s\n[\n", i3barHeader); err != nil {
+			return err
+		}
+	}
+
+	for first := true; ; first = false {
+		var err error
+		if format == "waybar" {
+			err = renderWaybar(w, filterMounts(m, filters), sortBy)
+		} else {
+			err = renderI3bar(w, filterMounts(m, filters), sortBy, first)
+		}
+		if err != nil {
+			return err
+		}
+
+		if interval == 0 {
+			break
+		}
+		time.Sleep(interval)
+
+		m, _, err = readMounts(paths, filters)
+		if err != nil {
+			return err
+		}
+	}
+
+	if format == "i3bar" {
+		_, err := fmt.Fprintln(w, "]")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRunStatusBar(t *testing.T) {
+	m := []Mount{
+		{Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/", Total: 100 << 30, Free: 50 << 30, Used: 50 << 30, Blocks: 100, BlockSize: 1 << 30},
+	}
+
+	var tt = []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "waybar",
+			expected: `{"text":"/ 50.0G","tooltip":"/: 50.0G / 100.0G used (50.0%), 50.0G avail","class":"warning","percentage":50}` + "\n",
+		},
+		{
+			format: "i3bar",
+			expected: `{"version":1}` + "\n[\n" +
+				`[{"full_text":"/ 50.0G","short_text":"50.0G","color":"#DBAB79","urgent":false,"name":"duf","instance":"/"}]` + "\n]\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := runStatusBar(&buf, tc.format, m, nil, FilterOptions{}, 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, buf.String())
+			}
+		})
+	}
+}
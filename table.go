@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -19,36 +22,56 @@ type TableOptions struct {
 	SortBy    int
 	Style     table.Style
 	StyleName string
+	Format    string
+	GroupBy   string
+	Tree      string
+	Samples   []Sample
+	Output    io.Writer
 }
 
 // Column defines a column.
 type Column struct {
-	ID        string
-	Name      string
-	SortIndex int
-	Width     int
+	ID    string
+	Name  string
+	Width int
 }
 
-// "Mounted on", "Size", "Used", "Avail", "Use%", "Inodes", "IUsed", "IAvail", "IUse%", "Type", "Filesystem"
-// mountpoint, size, used, avail, usage, inodes, inodes_used, inodes_avail, inodes_usage, type, filesystem
+// "Mounted on", "Size", "Used", "Avail", "Use%", "Inodes", "IUsed", "IAvail", "IUse%", "Type", "Filesystem", "ΔUsed", "Rate", "ETA", "Trend", "Host", "ID", "Parent", "Devno", "Root", "Propagation", "Bind source", "Also mounted at"
+// mountpoint, size, used, avail, usage, inodes, inodes_used, inodes_avail, inodes_usage, type, filesystem, used_delta, rate, eta, trend, host, id, parent, devno, root, propagation, bind_source, also_mounted_at
 var columns = []Column{
-	{ID: "mountpoint", Name: "Mounted on", SortIndex: 1},
-	{ID: "size", Name: "Size", SortIndex: 12, Width: 7},
-	{ID: "used", Name: "Used", SortIndex: 13, Width: 7},
-	{ID: "avail", Name: "Avail", SortIndex: 14, Width: 7},
-	{ID: "usage", Name: "Use%", SortIndex: 15, Width: 6},
-	{ID: "inodes", Name: "Inodes", SortIndex: 16, Width: 7},
-	{ID: "inodes_used", Name: "IUsed", SortIndex: 17, Width: 7},
-	{ID: "inodes_avail", Name: "IAvail", SortIndex: 18, Width: 7},
-	{ID: "inodes_usage", Name: "IUse%", SortIndex: 19, Width: 6},
-	{ID: "type", Name: "Type", SortIndex: 10},
-	{ID: "filesystem", Name: "Filesystem", SortIndex: 11},
+	{ID: "mountpoint", Name: "Mounted on"},
+	{ID: "size", Name: "Size", Width: 7},
+	{ID: "used", Name: "Used", Width: 7},
+	{ID: "avail", Name: "Avail", Width: 7},
+	{ID: "usage", Name: "Use%", Width: 6},
+	{ID: "inodes", Name: "Inodes", Width: 7},
+	{ID: "inodes_used", Name: "IUsed", Width: 7},
+	{ID: "inodes_avail", Name: "IAvail", Width: 7},
+	{ID: "inodes_usage", Name: "IUse%", Width: 6},
+	{ID: "type", Name: "Type"},
+	{ID: "filesystem", Name: "Filesystem"},
+	{ID: "used_delta", Name: "ΔUsed", Width: 8},
+	{ID: "rate", Name: "Rate", Width: 10},
+	{ID: "eta", Name: "ETA", Width: 7},
+	{ID: "trend", Name: "Trend"},
+	{ID: "host", Name: "Host"},
+	{ID: "id", Name: "ID", Width: 4},
+	{ID: "parent", Name: "Parent", Width: 6},
+	{ID: "devno", Name: "Devno"},
+	{ID: "root", Name: "Root"},
+	{ID: "propagation", Name: "Propagation"},
+	{ID: "bind_source", Name: "Bind source"},
+	{ID: "also_mounted_at", Name: "Also mounted at"},
 }
 
 // initializeTable sets up the table writer with initial configurations.
 func initializeTable(tab table.Writer, opts TableOptions) {
 	tab.SetAllowedRowLength(int(*width))
-	tab.SetOutputMirror(os.Stdout)
+	if opts.Output != nil {
+		tab.SetOutputMirror(opts.Output)
+	} else {
+		tab.SetOutputMirror(os.Stdout)
+	}
 	tab.Style().Options.SeparateColumns = true
 	tab.SetStyle(opts.Style)
 }
@@ -63,24 +86,17 @@ func appendHeaders(tab table.Writer) {
 }
 
 // appendRows adds data rows to the table for each mount.
-func appendRows(tab table.Writer, m []Mount) {
+func appendRows(tab table.Writer, m []Mount, opts TableOptions) {
 	for _, v := range m {
-		var usage, inodeUsage float64
-		if v.Total > 0 {
-			usage = float64(v.Used) / float64(v.Total)
-			if usage > 1.0 {
-				usage = 1.0
-			}
-		}
-		if v.Inodes > 0 {
-			inodeUsage = float64(v.InodesUsed) / float64(v.Inodes)
-			if inodeUsage > 1.0 {
-				inodeUsage = 1.0
-			}
+		usage := usageRatio(v.Used, v.Total)
+		inodeUsage := usageRatio(v.InodesUsed, v.Inodes)
+		eta, ok := timeToFull(v, opts.Samples)
+		if !ok {
+			eta = noETA
 		}
 
 		tab.AppendRow([]interface{}{
-			termenv.String(v.Mountpoint).Foreground(theme.colorBlue), // mounted on
+			termenv.String(mountLabel(v)).Foreground(theme.colorBlue), // mounted on
 			v.Total,      // size
 			v.Used,       // used
 			v.Free,       // avail
@@ -91,14 +107,18 @@ func appendRows(tab table.Writer, m []Mount) {
 			inodeUsage,   // inodes use%
 			termenv.String(v.Fstype).Foreground(theme.colorGray), // type
 			termenv.String(v.Device).Foreground(theme.colorGray), // filesystem
-			v.Total,      // size sorting helper
-			v.Used,       // used sorting helper
-			v.Free,       // avail sorting helper
-			usage,        // use% sorting helper
-			v.Inodes,     // inodes sorting helper
-			v.InodesUsed, // inodes used sorting helper
-			v.InodesFree, // inodes avail sorting helper
-			inodeUsage,   // inodes use% sorting helper
+			deltaString(usedDelta(v, opts.Samples)),              // used delta
+			rateString(usedRate(v, opts.Samples)),                // rate
+			eta,                                                  // eta
+			usageTrend(v, opts.Samples),                          // trend
+			mountHost(v),                                         // host
+			v.MountID,                                            // id
+			v.ParentID,                                           // parent
+			v.Devno,                                              // devno
+			v.Root,                                               // root
+			v.Propagation,                                        // propagation
+			v.BindSource,                                         // bind source
+			strings.Join(v.AlsoMountedAt, ", "),                  // also mounted at
 		})
 	}
 }
@@ -113,7 +133,7 @@ func computeMaxContentWidths(m []Mount, opts TableOptions) map[int]int {
 	}
 	for _, v := range m {
 		if inColumns(opts.Columns, 1) {
-			if w := runewidth.StringWidth(v.Mountpoint); w > maxColContent[1] {
+			if w := runewidth.StringWidth(mountLabel(v)); w > maxColContent[1] {
 				maxColContent[1] = w
 			}
 		}
@@ -133,14 +153,7 @@ func computeMaxContentWidths(m []Mount, opts TableOptions) map[int]int {
 			}
 		}
 		if inColumns(opts.Columns, 5) {
-			var usage float64
-			if v.Total > 0 {
-				usage = float64(v.Used) / float64(v.Total)
-				if usage > 1.0 {
-					usage = 1.0
-				}
-			}
-			percentStr := fmt.Sprintf("%.1f%%", usage*100)
+			percentStr := fmt.Sprintf("%.1f%%", usageRatio(v.Used, v.Total)*100)
 			if w := runewidth.StringWidth(percentStr); w > maxColContent[5] {
 				maxColContent[5] = w
 			}
@@ -161,14 +174,7 @@ func computeMaxContentWidths(m []Mount, opts TableOptions) map[int]int {
 			}
 		}
 		if inColumns(opts.Columns, 9) {
-			var usage float64
-			if v.Inodes > 0 {
-				usage = float64(v.InodesUsed) / float64(v.Inodes)
-				if usage > 1.0 {
-					usage = 1.0
-				}
-			}
-			percentStr := fmt.Sprintf("%.1f%%", usage*100)
+			percentStr := fmt.Sprintf("%.1f%%", usageRatio(v.InodesUsed, v.Inodes)*100)
 			if w := runewidth.StringWidth(percentStr); w > maxColContent[9] {
 				maxColContent[9] = w
 			}
@@ -183,11 +189,72 @@ func computeMaxContentWidths(m []Mount, opts TableOptions) map[int]int {
 				maxColContent[11] = w
 			}
 		}
+		if inColumns(opts.Columns, 12) {
+			if w := runewidth.StringWidth(deltaString(usedDelta(v, opts.Samples))); w > maxColContent[12] {
+				maxColContent[12] = w
+			}
+		}
+		if inColumns(opts.Columns, 13) {
+			if w := runewidth.StringWidth(rateString(usedRate(v, opts.Samples))); w > maxColContent[13] {
+				maxColContent[13] = w
+			}
+		}
+		if inColumns(opts.Columns, 14) {
+			if w := runewidth.StringWidth(etaString(timeToFull(v, opts.Samples))); w > maxColContent[14] {
+				maxColContent[14] = w
+			}
+		}
+		if inColumns(opts.Columns, 15) {
+			// one character per sample
+			if w := len(usageTrend(v, opts.Samples)); w > maxColContent[15] {
+				maxColContent[15] = w
+			}
+		}
+		if inColumns(opts.Columns, 16) {
+			if w := runewidth.StringWidth(mountHost(v)); w > maxColContent[16] {
+				maxColContent[16] = w
+			}
+		}
+		if inColumns(opts.Columns, 17) {
+			if w := runewidth.StringWidth(strconv.Itoa(v.MountID)); w > maxColContent[17] {
+				maxColContent[17] = w
+			}
+		}
+		if inColumns(opts.Columns, 18) {
+			if w := runewidth.StringWidth(strconv.Itoa(v.ParentID)); w > maxColContent[18] {
+				maxColContent[18] = w
+			}
+		}
+		if inColumns(opts.Columns, 19) {
+			if w := runewidth.StringWidth(v.Devno); w > maxColContent[19] {
+				maxColContent[19] = w
+			}
+		}
+		if inColumns(opts.Columns, 20) {
+			if w := runewidth.StringWidth(v.Root); w > maxColContent[20] {
+				maxColContent[20] = w
+			}
+		}
+		if inColumns(opts.Columns, 21) {
+			if w := runewidth.StringWidth(v.Propagation); w > maxColContent[21] {
+				maxColContent[21] = w
+			}
+		}
+		if inColumns(opts.Columns, 22) {
+			if w := runewidth.StringWidth(v.BindSource); w > maxColContent[22] {
+				maxColContent[22] = w
+			}
+		}
+		if inColumns(opts.Columns, 23) {
+			if w := runewidth.StringWidth(strings.Join(v.AlsoMountedAt, ", ")); w > maxColContent[23] {
+				maxColContent[23] = w
+			}
+		}
 	}
 	return maxColContent
 }
 
-// computeAssignedWidths computes the assigned widths for dynamic columns (1, 10, 11).
+// computeAssignedWidths computes the assigned widths for dynamic columns (1, 10, 11, 15, 20, 22, 23).
 func computeAssignedWidths(maxColContent map[int]int, opts TableOptions) (map[int]int, int) {
 	visibleCols := append([]int{}, opts.Columns...)
 	nVis := len(visibleCols)
@@ -200,9 +267,9 @@ func computeAssignedWidths(maxColContent map[int]int, opts TableOptions) (map[in
 
 	// Determine targets and their need
 	targets := []int{}
-	weights := map[int]float64{1: 0.4, 10: 0.2, 11: 0.4}
+	weights := map[int]float64{1: 0.4, 10: 0.2, 11: 0.4, 15: 0.3, 20: 0.3, 22: 0.4, 23: 0.4}
 	weightSum := 0.0
-	for _, t := range []int{1, 10, 11} {
+	for _, t := range []int{1, 10, 11, 15, 20, 22, 23} {
 		if inColumns(opts.Columns, t) {
 			targets = append(targets, t)
 			weightSum += weights[t]
@@ -212,7 +279,7 @@ func computeAssignedWidths(maxColContent map[int]int, opts TableOptions) (map[in
 	// Sum fixed widths of non-target visible columns
 	fixedContentWidth := 0
 	for _, ci := range visibleCols {
-		if ci == 1 || ci == 10 || ci == 11 {
+		if _, ok := weights[ci]; ok {
 			continue
 		}
 		fixedContentWidth += maxColContent[ci]
@@ -269,7 +336,11 @@ func computeAssignedWidths(maxColContent map[int]int, opts TableOptions) (map[in
 }
 
 // setColumnConfigs configures the columns for the table.
-func setColumnConfigs(tab table.Writer, maxColContent map[int]int, assigned map[int]int, opts TableOptions, barTransformerFunc func(interface{}) string) {
+func setColumnConfigs(tab table.Writer, maxColContent map[int]int, assigned map[int]int, opts TableOptions, barTransformerFunc, inodeBarTransformerFunc func(interface{}) string) {
+	trendTransformer := func(val interface{}) string {
+		return sparkline(val.([]float64), assigned[15], opts.StyleName, true)
+	}
+
 	cfgs := []table.ColumnConfig{
 		{Number: 1, Hidden: !inColumns(opts.Columns, 1), WidthMax: assigned[1]},
 		{Number: 2, Hidden: !inColumns(opts.Columns, 2), Transformer: sizeTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[2]},
@@ -279,17 +350,21 @@ func setColumnConfigs(tab table.Writer, maxColContent map[int]int, assigned map[
 		{Number: 6, Hidden: !inColumns(opts.Columns, 6), Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[6]},
 		{Number: 7, Hidden: !inColumns(opts.Columns, 7), Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[7]},
 		{Number: 8, Hidden: !inColumns(opts.Columns, 8), Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[8]},
-		{Number: 9, Hidden: !inColumns(opts.Columns, 9), Transformer: barTransformerFunc, AlignHeader: text.AlignCenter, WidthMax: maxColContent[9]},
+		{Number: 9, Hidden: !inColumns(opts.Columns, 9), Transformer: inodeBarTransformerFunc, AlignHeader: text.AlignCenter, WidthMax: maxColContent[9]},
 		{Number: 10, Hidden: !inColumns(opts.Columns, 10), WidthMax: assigned[10]},
 		{Number: 11, Hidden: !inColumns(opts.Columns, 11), WidthMax: assigned[11]},
-		{Number: 12, Hidden: true}, // sortBy helper for size
-		{Number: 13, Hidden: true}, // sortBy helper for used
-		{Number: 14, Hidden: true}, // sortBy helper for avail
-		{Number: 15, Hidden: true}, // sortBy helper for usage
-		{Number: 16, Hidden: true}, // sortBy helper for inodes size
-		{Number: 17, Hidden: true}, // sortBy helper for inodes used
-		{Number: 18, Hidden: true}, // sortBy helper for inodes avail
-		{Number: 19, Hidden: true}, // sortBy helper for inodes usage
+		{Number: 12, Hidden: !inColumns(opts.Columns, 12), Transformer: deltaTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[12]},
+		{Number: 13, Hidden: !inColumns(opts.Columns, 13), Transformer: deltaTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[13]},
+		{Number: 14, Hidden: !inColumns(opts.Columns, 14), Transformer: etaTransformer, Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[14]},
+		{Number: 15, Hidden: !inColumns(opts.Columns, 15), Transformer: trendTransformer, Align: text.AlignRight, WidthMax: assigned[15]},
+		{Number: 16, Hidden: !inColumns(opts.Columns, 16), WidthMax: maxColContent[16]},
+		{Number: 17, Hidden: !inColumns(opts.Columns, 17), Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[17]},
+		{Number: 18, Hidden: !inColumns(opts.Columns, 18), Align: text.AlignRight, AlignHeader: text.AlignRight, WidthMax: maxColContent[18]},
+		{Number: 19, Hidden: !inColumns(opts.Columns, 19), WidthMax: maxColContent[19]},
+		{Number: 20, Hidden: !inColumns(opts.Columns, 20), WidthMax: assigned[20]},
+		{Number: 21, Hidden: !inColumns(opts.Columns, 21), WidthMax: maxColContent[21]},
+		{Number: 22, Hidden: !inColumns(opts.Columns, 22), WidthMax: assigned[22]},
+		{Number: 23, Hidden: !inColumns(opts.Columns, 23), WidthMax: assigned[23]},
 	}
 	tab.SetColumnConfigs(cfgs)
 }
@@ -299,7 +374,11 @@ func printTable(title string, m []Mount, opts TableOptions) {
 	tab := table.NewWriter()
 	initializeTable(tab, opts)
 	appendHeaders(tab)
-	appendRows(tab, m)
+	if opts.Tree == "" {
+		// trees are already sorted
+		sortMounts(m, opts.SortBy, opts.Samples)
+	}
+	appendRows(tab, m, opts)
 
 	if tab.Length() == 0 {
 		return
@@ -339,8 +418,8 @@ func printTable(title string, m []Mount, opts TableOptions) {
 		}
 	}
 
-	// Define barTransformerFunc
-	barTransformerFunc := func(val interface{}) string {
+	// Define barTransformer
+	barTransformer := func(val interface{}, thresholds string) string {
 		usage := val.(float64)
 		if barWidth <= 0 {
 			s := fmt.Sprintf("%*s", percentWidth, fmt.Sprintf("%.1f%%", usage*100))
@@ -386,14 +465,13 @@ func printTable(title string, m []Mount, opts TableOptions) {
 		}
 
 		// Apply colors
-		redUsage, _ := strconv.ParseFloat(strings.Split(*usageThreshold, ",")[1], 64)
-		yellowUsage, _ := strconv.ParseFloat(strings.Split(*usageThreshold, ",")[0], 64)
+		level := usageLevel(usage, thresholds)
 
 		var fgColor termenv.Color
-		switch {
-		case usage >= redUsage:
+		switch level {
+		case levelCritical:
 			fgColor = theme.colorRed
-		case usage >= yellowUsage:
+		case levelWarning:
 			fgColor = theme.colorYellow
 		default:
 			fgColor = theme.colorGreen
@@ -405,10 +483,10 @@ func printTable(title string, m []Mount, opts TableOptions) {
 			// Add background to filled part to prevent black spaces in half blocks
 			// Use a background color that complements the foreground
 			var bgColor termenv.Color
-			switch {
-			case usage >= redUsage:
+			switch level {
+			case levelCritical:
 				bgColor = theme.colorBgRed
-			case usage >= yellowUsage:
+			case levelWarning:
 				bgColor = theme.colorBgYellow
 			default:
 				bgColor = theme.colorBgGreen
@@ -421,22 +499,18 @@ func printTable(title string, m []Mount, opts TableOptions) {
 		s := fmt.Sprintf(format, filledPart, emptyPart, percentWidth, fmt.Sprintf("%.1f%%", usage*100))
 		return termenv.String(s).String()
 	}
+	barTransformerFunc := func(val interface{}) string {
+		return barTransformer(val, *usageThreshold)
+	}
+	inodeBarTransformerFunc := func(val interface{}) string {
+		return barTransformer(val, *inodesUsageThreshold)
+	}
 
-	setColumnConfigs(tab, maxColContent, assigned, opts, barTransformerFunc)
+	setColumnConfigs(tab, maxColContent, assigned, opts, barTransformerFunc, inodeBarTransformerFunc)
 
-	suffix := "device"
-	if tab.Length() > 1 {
-		suffix = "devices"
-	}
-	tab.SetTitle("%d %s %s", tab.Length(), title, suffix)
+	tab.SetTitle("%s", tableTitle(title, tab.Length(), opts.GroupBy))
 
 	// tab.AppendFooter(table.Row{fmt.Sprintf("%d %s", tab.Length(), title)})
-	sortMode := table.Asc
-	if opts.SortBy >= 12 {
-		sortMode = table.AscNumeric
-	}
-
-	tab.SortBy([]table.SortBy{{Number: opts.SortBy, Mode: sortMode}})
 	tab.Render()
 }
 
@@ -450,18 +524,75 @@ func spaceTransformer(val interface{}) string {
 	free := val.(uint64)
 
 	s := termenv.String(sizeToString(free))
+	switch availLevel(free) {
+	case levelCritical:
+		s = s.Foreground(theme.colorRed)
+	case levelWarning:
+		s = s.Foreground(theme.colorYellow)
+	default:
+		s = s.Foreground(theme.colorGreen)
+	}
+
+	return s.String()
+}
+
+// Threshold levels a value can reach, in increasing severity.
+const (
+	levelOK = iota
+	levelWarning
+	levelCritical
+)
+
+// levelNames maps threshold levels to their names.
+var levelNames = map[int]string{
+	levelOK:       "ok",
+	levelWarning:  "warning",
+	levelCritical: "critical",
+}
+
+// availLevel returns the threshold level of the available space, according to
+// the avail-threshold flag.
+func availLevel(free uint64) int {
 	redAvail, _ := stringToSize(strings.Split(*availThreshold, ",")[1])
 	yellowAvail, _ := stringToSize(strings.Split(*availThreshold, ",")[0])
+
 	switch {
 	case free < redAvail:
-		s = s.Foreground(theme.colorRed)
+		return levelCritical
 	case free < yellowAvail:
-		s = s.Foreground(theme.colorYellow)
+		return levelWarning
 	default:
-		s = s.Foreground(theme.colorGreen)
+		return levelOK
+	}
+}
+
+// usageLevel returns the threshold level of a usage ratio. thresholds are
+// supplied in the format of the usage-threshold flag.
+func usageLevel(usage float64, thresholds string) int {
+	redUsage, _ := strconv.ParseFloat(strings.Split(thresholds, ",")[1], 64)
+	yellowUsage, _ := strconv.ParseFloat(strings.Split(thresholds, ",")[0], 64)
+
+	switch {
+	case usage >= redUsage:
+		return levelCritical
+	case usage >= yellowUsage:
+		return levelWarning
+	default:
+		return levelOK
+	}
+}
+
+// usageRatio returns the ratio of used to total, capped at 1.0.
+func usageRatio(used, total uint64) float64 {
+	if total == 0 {
+		return 0
 	}
 
-	return s.String()
+	usage := float64(used) / float64(total)
+	if usage > 1.0 {
+		usage = 1.0
+	}
+	return usage
 }
 
 // inColumns return true if the column with index i is in the slice of visible
@@ -537,26 +668,168 @@ func stringToSize(s string) (size uint64, err error) {
 	return
 }
 
-// stringToColumn converts a column name to its index.
-func stringToColumn(s string) (int, error) {
-	s = strings.ToLower(s)
+// columnValue returns the plain-text value of the column with index col for
+// mount v. Sizes and usages are made human-readable if human is true. Columns
+// derived from previous samples are calculated from the samples supplied, and
+// trends drawn in the style styleName.
+func columnValue(v Mount, col int, human bool, samples []Sample, styleName string) string {
+	switch columns[col-1].ID {
+	case "mountpoint":
+		return mountLabel(v)
+	case "size":
+		return formatSize(v.Total, human)
+	case "used":
+		return formatSize(v.Used, human)
+	case "avail":
+		return formatSize(v.Free, human)
+	case "usage":
+		return formatUsage(usageRatio(v.Used, v.Total), human)
+	case "inodes":
+		return strconv.FormatUint(v.Inodes, 10)
+	case "inodes_used":
+		return strconv.FormatUint(v.InodesUsed, 10)
+	case "inodes_avail":
+		return strconv.FormatUint(v.InodesFree, 10)
+	case "inodes_usage":
+		return formatUsage(usageRatio(v.InodesUsed, v.Inodes), human)
+	case "type":
+		return v.Fstype
+	case "filesystem":
+		return v.Device
+	case "host":
+		return mountHost(v)
+	case "id":
+		return strconv.Itoa(v.MountID)
+	case "parent":
+		return strconv.Itoa(v.ParentID)
+	case "devno":
+		return v.Devno
+	case "root":
+		return v.Root
+	case "propagation":
+		return v.Propagation
+	case "bind_source":
+		return v.BindSource
+	case "also_mounted_at":
+		return strings.Join(v.AlsoMountedAt, ", ")
+	case "used_delta":
+		delta, ok := usedDelta(v, samples)
+		if !ok || human {
+			return deltaString(delta, ok)
+		}
+		return strconv.FormatInt(delta, 10)
+	case "rate":
+		rate, ok := usedRate(v, samples)
+		if !ok || human {
+			return rateString(rate, ok)
+		}
+		return strconv.FormatFloat(rate, 'f', 0, 64)
+	case "eta":
+		eta, ok := timeToFull(v, samples)
+		if !ok || human {
+			return etaString(eta, ok)
+		}
+		return strconv.FormatInt(int64(eta.Seconds()), 10)
+	case "trend":
+		points := usageTrend(v, samples)
+		return sparkline(points, len(points), styleName, false)
+	}
 
-	for i, v := range columns {
-		if v.ID == s {
-			return i + 1, nil
+	return ""
+}
+
+// formatSize returns size in bytes, or human-readable if human is true.
+func formatSize(size uint64, human bool) string {
+	if human {
+		return sizeToString(size)
+	}
+	return strconv.FormatUint(size, 10)
+}
+
+// formatUsage returns a usage ratio, or a percentage if human is true.
+func formatUsage(usage float64, human bool) string {
+	if human {
+		return fmt.Sprintf("%.1f%%", usage*100)
+	}
+	return strconv.FormatFloat(usage, 'f', 4, 64)
+}
+
+// sortMounts sorts the mounts by the column with index sortBy. Columns derived
+// from previous samples are sorted by the samples supplied.
+func sortMounts(m []Mount, sortBy int, samples []Sample) {
+	id := columns[sortBy-1].ID
+
+	key := func(v Mount) float64 {
+		switch id {
+		case "size":
+			return float64(v.Total)
+		case "used":
+			return float64(v.Used)
+		case "avail":
+			return float64(v.Free)
+		case "usage":
+			return usageRatio(v.Used, v.Total)
+		case "inodes":
+			return float64(v.Inodes)
+		case "inodes_used":
+			return float64(v.InodesUsed)
+		case "inodes_avail":
+			return float64(v.InodesFree)
+		case "inodes_usage":
+			return usageRatio(v.InodesUsed, v.Inodes)
+		case "used_delta":
+			delta, _ := usedDelta(v, samples)
+			return float64(delta)
+		case "rate":
+			rate, _ := usedRate(v, samples)
+			return rate
+		case "eta":
+			// mounts that aren't running full sort last
+			eta, ok := timeToFull(v, samples)
+			if !ok {
+				return math.Inf(1)
+			}
+			return eta.Seconds()
+		case "id":
+			return float64(v.MountID)
+		case "parent":
+			return float64(v.ParentID)
+		case "also_mounted_at":
+			return float64(len(v.AlsoMountedAt))
 		}
+		return 0
 	}
 
-	return 0, fmt.Errorf("unknown column: %s (valid: %s)", s, strings.Join(columnIDs(), ", "))
+	sort.SliceStable(m, func(i, j int) bool {
+		switch id {
+		case "mountpoint":
+			return m[i].Mountpoint < m[j].Mountpoint
+		case "type":
+			return m[i].Fstype < m[j].Fstype
+		case "filesystem":
+			return m[i].Device < m[j].Device
+		case "host":
+			return mountHost(m[i]) < mountHost(m[j])
+		case "devno":
+			return m[i].Devno < m[j].Devno
+		case "root":
+			return m[i].Root < m[j].Root
+		case "propagation":
+			return m[i].Propagation < m[j].Propagation
+		case "bind_source":
+			return m[i].BindSource < m[j].BindSource
+		}
+		return key(m[i]) < key(m[j])
+	})
 }
 
-// stringToSortIndex converts a column name to its sort index.
-func stringToSortIndex(s string) (int, error) {
+// stringToColumn converts a column name to its index.
+func stringToColumn(s string) (int, error) {
 	s = strings.ToLower(s)
 
-	for _, v := range columns {
+	for i, v := range columns {
 		if v.ID == s {
-			return v.SortIndex, nil
+			return i + 1, nil
 		}
 	}
 
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available in user-defined templates.
+var templateFuncs = template.FuncMap{
+	"human": sizeToString,
+	"percent": func(usage float64) string {
+		return formatUsage(usage, true)
+	},
+	"usage": func(m Mount) float64 {
+		return usageRatio(m.Used, m.Total)
+	},
+	"inodeUsage": func(m Mount) float64 {
+		return usageRatio(m.InodesUsed, m.Inodes)
+	},
+	"availLevel": func(m Mount) string {
+		return levelNames[availLevel(m.Free)]
+	},
+	"usageLevel": func(m Mount) string {
+		return levelNames[usageLevel(usageRatio(m.Used, m.Total), *usageThreshold)]
+	},
+	"inodeUsageLevel": func(m Mount) string {
+		return levelNames[usageLevel(usageRatio(m.InodesUsed, m.Inodes), *inodesUsageThreshold)]
+	},
+	"deviceType": func(m Mount) string {
+		return m.DeviceType
+	},
+}
+
+// parseTemplate parses a user-defined template, either supplied directly or
+// read from a file.
+func parseTemplate(tmpl, filename string) (*template.Template, error) {
+	if filename != "" {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the template: %s", err)
+		}
+		tmpl = string(b)
+	}
+
+	t, err := template.New("duf").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the template: %s", err)
+	}
+	return t, nil
+}
+
+// renderTemplate executes the template against all grouped mounts.
+func renderTemplate(w io.Writer, t *template.Template, deviceMounts map[string][]Mount, sortBy int) error {
+	m := []Mount{}
+	for _, devType := range groups {
+		m = append(m, deviceMounts[devType]...)
+	}
+	sortMounts(m, sortBy, nil)
+
+	if err := t.Execute(w, m); err != nil {
+		return fmt.Errorf("error executing the template: %s", err)
+	}
+	return nil
+}
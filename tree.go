@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Modes of the tree view.
+const (
+	treeCollapse = "collapse"
+	treeExpand   = "expand"
+)
+
+// mountNode is a mount in the tree of mounts.
+type mountNode struct {
+	mount    Mount
+	children []*mountNode
+	// number of pseudo filesystems collapsed into this node
+	collapsed int
+}
+
+// mountParents returns the index of the parent of every mount, or -1 for
+// mounts without a parent. Parents are identified by the mount IDs of the
+// mount table if available, otherwise by the closest mount point above.
+func mountParents(m []Mount) []int {
+	ids := make(map[int]int, len(m))
+	for i, v := range m {
+		if v.MountID > 0 {
+			ids[v.MountID] = i
+		}
+	}
+
+	parents := make([]int, len(m))
+	for i, v := range m {
+		parents[i] = -1
+		if p, ok := ids[v.ParentID]; ok && v.MountID > 0 && p != i {
+			parents[i] = p
+			continue
+		}
+
+		for j, pv := range m {
+			if pv.Mountpoint == v.Mountpoint || !isSubpath(v.Mountpoint, pv.Mountpoint) {
+				continue
+			}
+			if parents[i] < 0 || len(pv.Mountpoint) > len(m[parents[i]].Mountpoint) {
+				parents[i] = j
+			}
+		}
+	}
+
+	return parents
+}
+
+// isSubpath returns true if path is located beneath or at dir.
+func isSubpath(path, dir string) bool {
+	return path == dir || dir == "/" || strings.HasPrefix(path, dir+"/")
+}
+
+// mountTree arranges the visible mounts in a tree, in the order of m. Mounts
+// whose parents aren't visible are attached to their closest visible
+// ancestor. If collapse is true, pseudo filesystems mounted beneath other
+// pseudo filesystems are merged into the topmost one.
+func mountTree(m []Mount, visible func(Mount) bool, collapse bool) []*mountNode {
+	parents := mountParents(m)
+
+	// find the closest visible ancestor of every mount, giving up on
+	// (malformed) cyclic mount tables
+	visibleParents := make([]int, len(m))
+	for i := range m {
+		visibleParents[i] = -1
+		for p, n := parents[i], 0; p >= 0 && n < len(m); p, n = parents[p], n+1 {
+			if visible(m[p]) {
+				visibleParents[i] = p
+				break
+			}
+		}
+	}
+
+	// create the nodes, merging collapsed mounts into their ancestors
+	nodes := make([]*mountNode, len(m))
+	merged := make([]bool, len(m))
+	var node func(i int) *mountNode
+	node = func(i int) *mountNode {
+		if nodes[i] != nil {
+			return nodes[i]
+		}
+		nodes[i] = &mountNode{mount: m[i]}
+
+		if p := visibleParents[i]; p >= 0 && collapse {
+			parent := node(p)
+			if m[i].DeviceType == specialDevice && parent.mount.DeviceType == specialDevice {
+				parent.collapsed++
+				nodes[i] = parent
+				merged[i] = true
+			}
+		}
+		return nodes[i]
+	}
+
+	var roots []*mountNode
+	for i, v := range m {
+		if !visible(v) {
+			continue
+		}
+
+		n := node(i)
+		if merged[i] {
+			continue
+		}
+		if p := visibleParents[i]; p >= 0 {
+			parent := node(p)
+			parent.children = append(parent.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+
+	return roots
+}
+
+// flattenTree returns the mounts of the tree in depth-first order, labeled with
+// their mount points prefixed by the branches of the tree.
+func flattenTree(roots []*mountNode, styleName string) []Mount {
+	branch, last, line, space := "├─ ", "└─ ", "│  ", "   "
+	if styleName != "unicode" {
+		branch, last, line = "|- ", "`- ", "|  "
+	}
+
+	var m []Mount
+	var walk func(nodes []*mountNode, prefix string)
+	walk = func(nodes []*mountNode, prefix string) {
+		for i, n := range nodes {
+			p, cp := branch, line
+			if i == len(nodes)-1 {
+				p, cp = last, space
+			}
+
+			m = append(m, treeMount(n, prefix+p))
+			walk(n.children, prefix+cp)
+		}
+	}
+
+	// roots aren't indented
+	for _, n := range roots {
+		m = append(m, treeMount(n, ""))
+		walk(n.children, "")
+	}
+
+	return m
+}
+
+// treeMount returns the mount of a node, labeled with the branches leading to
+// it and the number of mounts collapsed into it.
+func treeMount(n *mountNode, prefix string) Mount {
+	v := n.mount
+	v.Label = prefix + v.Mountpoint
+	if n.collapsed > 0 {
+		v.Label += fmt.Sprintf(" (+%d)", n.collapsed)
+	}
+
+	return v
+}
+
+// treeGroup returns the device group of the mounts in a tree, or an empty
+// string if they belong to more than one group.
+func treeGroup(m []Mount, filters FilterOptions) string {
+	deviceMounts := filterMounts(m, filters)
+	if len(deviceMounts) != 1 {
+		return ""
+	}
+	for group := range deviceMounts {
+		return group
+	}
+	return ""
+}
+
+// renderTree renders the mounts as a tree, in a table per host if grouped by
+// host. Siblings are sorted by the sort column.
+func renderTree(m []Mount, filters FilterOptions, opts TableOptions) {
+	m = append([]Mount{}, m...)
+	sortMounts(m, opts.SortBy, opts.Samples)
+
+	titles := []string{""}
+	hostMounts := map[string][]Mount{"": m}
+	if opts.GroupBy == "host" {
+		titles = nil
+		hostMounts = map[string][]Mount{}
+		for _, v := range m {
+			host := mountHost(v)
+			if _, ok := hostMounts[host]; !ok {
+				titles = append(titles, host)
+			}
+			hostMounts[host] = append(hostMounts[host], v)
+		}
+		sort.Strings(titles)
+	}
+
+	visible := func(v Mount) bool {
+		return len(filterMounts([]Mount{v}, filters)) > 0
+	}
+
+	if opts.Format == "html" {
+		fmt.Println(htmlStyle)
+	}
+	for _, title := range titles {
+		roots := mountTree(hostMounts[title], visible, opts.Tree == treeCollapse)
+		tm := flattenTree(roots, opts.StyleName)
+		if opts.GroupBy != "host" {
+			title = treeGroup(tm, filters)
+		}
+
+		switch opts.Format {
+		case "markdown", "html":
+			printMarkupTable(title, tm, opts)
+		default:
+			printTable(title, tm, opts)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMountTree(t *testing.T) {
+	var tt = []struct {
+		name     string
+		mounts   []Mount
+		collapse bool
+		expected []string
+	}{
+		{
+			name: "mount ids",
+			mounts: []Mount{
+				{MountID: 1, ParentID: 0, Mountpoint: "/", DeviceType: localDevice},
+				{MountID: 2, ParentID: 1, Mountpoint: "/var", DeviceType: localDevice},
+				{MountID: 3, ParentID: 2, Mountpoint: "/var/lib/docker", DeviceType: localDevice},
+				{MountID: 4, ParentID: 1, Mountpoint: "/home", DeviceType: localDevice},
+			},
+			expected: []string{"/", "├─ /var", "│  └─ /var/lib/docker", "└─ /home"},
+		},
+		{
+			name: "mount points",
+			mounts: []Mount{
+				{Mountpoint: "/", DeviceType: localDevice},
+				{Mountpoint: "/var/lib/docker", DeviceType: localDevice},
+				{Mountpoint: "/var", DeviceType: localDevice},
+			},
+			expected: []string{"/", "└─ /var", "   └─ /var/lib/docker"},
+		},
+		{
+			name: "hidden parent",
+			mounts: []Mount{
+				{MountID: 1, ParentID: 0, Mountpoint: "/", DeviceType: localDevice},
+				{MountID: 2, ParentID: 1, Mountpoint: "/var", DeviceType: localDevice, Device: "hidden"},
+				{MountID: 3, ParentID: 2, Mountpoint: "/var/lib/docker", DeviceType: localDevice},
+			},
+			expected: []string{"/", "└─ /var/lib/docker"},
+		},
+		{
+			name: "collapse pseudo filesystems",
+			mounts: []Mount{
+				{MountID: 1, ParentID: 0, Mountpoint: "/", DeviceType: localDevice},
+				{MountID: 2, ParentID: 1, Mountpoint: "/sys", DeviceType: specialDevice},
+				{MountID: 3, ParentID: 2, Mountpoint: "/sys/fs/cgroup", DeviceType: specialDevice},
+				{MountID: 4, ParentID: 3, Mountpoint: "/sys/fs/cgroup/cpu", DeviceType: specialDevice},
+				{MountID: 5, ParentID: 3, Mountpoint: "/sys/fs/cgroup/fuse", DeviceType: fuseDevice},
+			},
+			collapse: true,
+			expected: []string{"/", "└─ /sys (+2)", "   └─ /sys/fs/cgroup/fuse"},
+		},
+	}
+
+	visible := func(v Mount) bool {
+		return v.Device != "hidden"
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var labels []string
+			for _, v := range flattenTree(mountTree(tc.mounts, visible, tc.collapse), "unicode") {
+				labels = append(labels, mountLabel(v))
+			}
+			if !reflect.DeepEqual(labels, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, labels)
+			}
+		})
+	}
+}
+
+func TestMountTreeSamples(t *testing.T) {
+	m := []Mount{
+		{MountID: 1, ParentID: 0, Mountpoint: "/", DeviceType: localDevice, Total: 100, Used: 10},
+		{MountID: 2, ParentID: 1, Mountpoint: "/var", DeviceType: localDevice, Total: 100, Used: 30},
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start, Usages: map[string]Usage{"/": {Used: 10, Total: 100}, "/var": {Used: 20, Total: 100}}},
+		{Time: start.Add(time.Minute), Usages: map[string]Usage{"/": {Used: 10, Total: 100}, "/var": {Used: 30, Total: 100}}},
+	}
+
+	tm := flattenTree(mountTree(m, func(Mount) bool { return true }, false), "unicode")
+	v := tm[1]
+	if mountLabel(v) != "└─ /var" || v.Mountpoint != "/var" {
+		t.Fatalf("expected /var labeled as a branch, got %q (%q)", mountLabel(v), v.Mountpoint)
+	}
+	if d, ok := usedDelta(v, samples); !ok || d != 10 {
+		t.Errorf("expected a used delta of 10, got %d (%v)", d, ok)
+	}
+	if p := usageTrend(v, samples); !reflect.DeepEqual(p, []float64{0.2, 0.3}) {
+		t.Errorf("expected trend [0.2 0.3], got %v", p)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// minTrendSpan is the smallest usage span covered by a sparkline, so small
+// fluctuations don't look like steep changes.
+const minTrendSpan = 0.01
+
+var (
+	trendLevels      = []rune("▁▂▃▄▅▆▇█")
+	asciiTrendLevels = []rune("_.-=#")
+)
+
+// usageTrend returns the usage ratios of mount v across the samples.
+func usageTrend(v Mount, samples []Sample) []float64 {
+	var points []float64
+	for _, s := range samples {
+		u, ok := s.Usages[v.Mountpoint]
+		if !ok || u.Total == 0 {
+			continue
+		}
+		points = append(points, usageRatio(u.Used, u.Total))
+	}
+
+	return points
+}
+
+// sparkline renders the last width usage ratios as a sparkline, scaled between
+// their minimum and maximum. If colored is true, each point is colored
+// according to the usage thresholds.
+func sparkline(points []float64, width int, styleName string, colored bool) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(points) > width {
+		points = points[len(points)-width:]
+	}
+	if len(points) == 0 {
+		return ""
+	}
+
+	levels := trendLevels
+	if styleName != "unicode" {
+		levels = asciiTrendLevels
+	}
+
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		lo = min(lo, p)
+		hi = max(hi, p)
+	}
+	span := max(hi-lo, minTrendSpan)
+
+	var sb strings.Builder
+	for _, p := range points {
+		i := int((p - lo) / span * float64(len(levels)-1))
+		s := string(levels[min(i, len(levels)-1)])
+		if !colored {
+			sb.WriteString(s)
+			continue
+		}
+
+		switch usageLevel(p, *usageThreshold) {
+		case levelCritical:
+			sb.WriteString(termenv.String(s).Foreground(theme.colorRed).String())
+		case levelWarning:
+			sb.WriteString(termenv.String(s).Foreground(theme.colorYellow).String())
+		default:
+			sb.WriteString(termenv.String(s).Foreground(theme.colorGreen).String())
+		}
+	}
+
+	return sb.String()
+}
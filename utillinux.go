@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonUint is an unsigned integer, which util-linux tools print either as a
+// number or as a string, depending on their version. Percentages are stripped
+// of their percent sign, missing values are zero.
+type jsonUint uint64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (u *jsonUint) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSuffix(strings.Trim(string(b), `"`), "%")
+	if s == "null" || s == "" {
+		*u = 0
+		return nil
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number: %s", b)
+	}
+	*u = jsonUint(n)
+	return nil
+}
+
+// findmntFilesystem is a filesystem in the output of findmnt --json -b.
+type findmntFilesystem struct {
+	Target   string              `json:"target"`
+	Source   string              `json:"source"`
+	Fstype   string              `json:"fstype"`
+	Options  string              `json:"options"`
+	Size     jsonUint            `json:"size"`
+	Used     jsonUint            `json:"used"`
+	Avail    jsonUint            `json:"avail"`
+	Children []findmntFilesystem `json:"children"`
+}
+
+// parseFindmnt converts the filesystems listed by findmnt into mounts,
+// flattening the tree of submounts. Sizes are only available if findmnt
+// was asked for them, e.g. with -o TARGET,SOURCE,FSTYPE,OPTIONS,SIZE,USED,AVAIL.
+func parseFindmnt(data []byte) ([]Mount, error) {
+	var filesystems []findmntFilesystem
+	if err := json.Unmarshal(data, &filesystems); err != nil {
+		return nil, err
+	}
+
+	var m []Mount
+	var walk func(filesystems []findmntFilesystem)
+	walk = func(filesystems []findmntFilesystem) {
+		for _, fs := range filesystems {
+			m = append(m, Mount{
+				Device:     fs.Source,
+				DeviceType: guessDeviceType(fs.Source, fs.Fstype),
+				Mountpoint: fs.Target,
+				Fstype:     fs.Fstype,
+				Opts:       fs.Options,
+				Total:      uint64(fs.Size),
+				Used:       uint64(fs.Used),
+				Free:       uint64(fs.Avail),
+				// sizes are printed in bytes
+				Blocks:    uint64(fs.Size),
+				BlockSize: 1,
+			})
+
+			walk(fs.Children)
+		}
+	}
+	walk(filesystems)
+
+	return m, nil
+}
+
+// lsblkDevice is a block device in the output of lsblk --json -b.
+type lsblkDevice struct {
+	Name        string        `json:"name"`
+	Path        string        `json:"path"`
+	Fstype      string        `json:"fstype"`
+	Size        jsonUint      `json:"size"`
+	FsSize      jsonUint      `json:"fssize"`
+	FsUsed      jsonUint      `json:"fsused"`
+	FsAvail     jsonUint      `json:"fsavail"`
+	FsUse       jsonUint      `json:"fsuse%"`
+	Mountpoint  string        `json:"mountpoint"`
+	Mountpoints []string      `json:"mountpoints"`
+	Children    []lsblkDevice `json:"children"`
+}
+
+// parseLsblk converts the mounted block devices listed by lsblk into mounts,
+// flattening the tree of partitions and holders. The usage is most accurate
+// with -o NAME,PATH,FSTYPE,FSSIZE,FSUSED,FSAVAIL,MOUNTPOINTS.
+func parseLsblk(data []byte) ([]Mount, error) {
+	var devices []lsblkDevice
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, err
+	}
+
+	var m []Mount
+	var walk func(devices []lsblkDevice)
+	walk = func(devices []lsblkDevice) {
+		for _, d := range devices {
+			device := d.Path
+			if device == "" {
+				device = "/dev/" + d.Name
+			}
+
+			total := uint64(d.FsSize)
+			if total == 0 {
+				total = uint64(d.Size)
+			}
+			used := uint64(d.FsUsed)
+			switch {
+			case used > 0:
+			case d.FsUse > 0:
+				used = total * uint64(d.FsUse) / 100
+			case d.FsAvail > 0 && total > uint64(d.FsAvail):
+				used = total - uint64(d.FsAvail)
+			}
+
+			// older versions of lsblk only print a single mount point
+			mountpoints := d.Mountpoints
+			if len(mountpoints) == 0 {
+				mountpoints = []string{d.Mountpoint}
+			}
+			for _, mp := range mountpoints {
+				// skip unmounted devices and swap
+				if mp == "" || strings.HasPrefix(mp, "[") {
+					continue
+				}
+
+				m = append(m, Mount{
+					Device:     device,
+					DeviceType: guessDeviceType(device, d.Fstype),
+					Mountpoint: mp,
+					Fstype:     d.Fstype,
+					Total:      total,
+					Used:       used,
+					Free:       uint64(d.FsAvail),
+					// sizes are printed in bytes
+					Blocks:    total,
+					BlockSize: 1,
+				})
+			}
+
+			walk(d.Children)
+		}
+	}
+	walk(devices)
+
+	return m, nil
+}
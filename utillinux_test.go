@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFindmnt(t *testing.T) {
+	data := `[
+		{"target": "/", "source": "/dev/sda1", "fstype": "ext4", "options": "rw", "size": 1000, "used": 600, "avail": 300,
+		 "children": [
+			{"target": "/home", "source": "/dev/sda2", "fstype": "xfs", "options": "rw,noatime", "size": "2000", "used": "100", "avail": "1900"},
+			{"target": "/proc", "source": "proc", "fstype": "proc", "options": "rw"}
+		 ]}
+	]`
+
+	m, err := parseFindmnt([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Mount{
+		{Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/", Fstype: "ext4", Opts: "rw", Total: 1000, Used: 600, Free: 300, Blocks: 1000, BlockSize: 1},
+		{Device: "/dev/sda2", DeviceType: localDevice, Mountpoint: "/home", Fstype: "xfs", Opts: "rw,noatime", Total: 2000, Used: 100, Free: 1900, Blocks: 2000, BlockSize: 1},
+		{Device: "proc", DeviceType: specialDevice, Mountpoint: "/proc", Fstype: "proc", Opts: "rw", BlockSize: 1},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+}
+
+func TestParseLsblk(t *testing.T) {
+	data := `[
+		{"name": "sda", "path": "/dev/sda", "size": 5000, "mountpoints": [null],
+		 "children": [
+			{"name": "sda1", "path": "/dev/sda1", "fstype": "ext4", "fssize": 1000, "fsused": 600, "fsavail": 300, "mountpoints": ["/", "/mnt/root"]},
+			{"name": "sda2", "fstype": "swap", "size": 4000, "mountpoint": "[SWAP]"}
+		 ]},
+		{"name": "sdb", "fstype": "vfat", "size": 200, "fsavail": 150, "fsuse%": "25%", "mountpoint": "/boot/efi"}
+	]`
+
+	m, err := parseLsblk([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Mount{
+		{Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/", Fstype: "ext4", Total: 1000, Used: 600, Free: 300, Blocks: 1000, BlockSize: 1},
+		{Device: "/dev/sda1", DeviceType: localDevice, Mountpoint: "/mnt/root", Fstype: "ext4", Total: 1000, Used: 600, Free: 300, Blocks: 1000, BlockSize: 1},
+		{Device: "/dev/sdb", DeviceType: localDevice, Mountpoint: "/boot/efi", Fstype: "vfat", Total: 200, Used: 50, Free: 150, Blocks: 200, BlockSize: 1},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+// maxWatchSamples is the amount of samples kept during a watch session.
+const maxWatchSamples = 120
+
+// Usage holds the usage of a single mount.
+type Usage struct {
+	Total      uint64 `json:"total"`
+	Used       uint64 `json:"used"`
+	Free       uint64 `json:"free"`
+	Inodes     uint64 `json:"inodes"`
+	InodesUsed uint64 `json:"inodes_used"`
+	InodesFree uint64 `json:"inodes_free"`
+}
+
+// Sample holds the usage of all mounts at a point in time, keyed by their
+// mount points.
+type Sample struct {
+	Time   time.Time        `json:"time"`
+	Usages map[string]Usage `json:"usages"`
+}
+
+// newSample creates a sample of the mounts, taken at time t.
+func newSample(t time.Time, m []Mount) Sample {
+	s := Sample{
+		Time:   t,
+		Usages: make(map[string]Usage, len(m)),
+	}
+	for _, v := range m {
+		s.Usages[v.Mountpoint] = Usage{
+			Total:      v.Total,
+			Used:       v.Used,
+			Free:       v.Free,
+			Inodes:     v.Inodes,
+			InodesUsed: v.InodesUsed,
+			InodesFree: v.InodesFree,
+		}
+	}
+
+	return s
+}
+
+// usedDelta returns how much the used space of mount v changed between the
+// last two samples.
+func usedDelta(v Mount, samples []Sample) (int64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	prev, ok := samples[len(samples)-2].Usages[v.Mountpoint]
+	if !ok {
+		return 0, false
+	}
+	cur, ok := samples[len(samples)-1].Usages[v.Mountpoint]
+	if !ok {
+		return 0, false
+	}
+
+	return int64(cur.Used) - int64(prev.Used), true //nolint:gosec // sizes fit into int64
+}
+
+// usedRate returns how fast the used space of mount v changed between the last
+// two samples, in bytes per second.
+func usedRate(v Mount, samples []Sample) (float64, bool) {
+	delta, ok := usedDelta(v, samples)
+	if !ok {
+		return 0, false
+	}
+
+	elapsed := samples[len(samples)-1].Time.Sub(samples[len(samples)-2].Time).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+
+	return float64(delta) / elapsed, true
+}
+
+// deltaString prettifies a signed size.
+func deltaString(delta int64, ok bool) string {
+	switch {
+	case !ok:
+		return ""
+	case delta > 0:
+		return "+" + sizeToString(uint64(delta))
+	case delta < 0:
+		return "-" + sizeToString(uint64(-delta))
+	default:
+		return sizeToString(0)
+	}
+}
+
+// rateString prettifies a signed rate in bytes per second.
+func rateString(rate float64, ok bool) string {
+	if !ok {
+		return ""
+	}
+
+	return deltaString(int64(math.Round(rate)), true) + "/s"
+}
+
+// deltaTransformer colors growing sizes red and shrinking sizes green.
+func deltaTransformer(val interface{}) string {
+	s := termenv.String(val.(string))
+	switch {
+	case strings.HasPrefix(val.(string), "+"):
+		s = s.Foreground(theme.colorRed)
+	case strings.HasPrefix(val.(string), "-"):
+		s = s.Foreground(theme.colorGreen)
+	}
+
+	return s.String()
+}
+
+// watchTables reads the mount table every interval and redraws the tables in
+// place, until interrupted. If autoWidth is true, the tables adjust to the
+// terminal's width whenever it changes.
+func watchTables(interval time.Duration, paths []string, filters FilterOptions, opts TableOptions, autoWidth bool) error {
+	out := termenv.NewOutput(os.Stdout)
+	out.AltScreen()
+	out.HideCursor()
+	defer func() {
+		out.ShowCursor()
+		out.ExitAltScreen()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+	defer signal.Stop(resize)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var m []Mount
+	var samples []Sample
+	sample := func() error {
+		var err error
+		m, _, err = readMounts(paths, filters)
+		if err != nil {
+			return err
+		}
+
+		samples = append(samples, newSample(time.Now(), m))
+		if len(samples) > maxWatchSamples {
+			samples = samples[1:]
+		}
+		return nil
+	}
+	redraw := func() {
+		if autoWidth {
+			*width = terminalWidth()
+		}
+
+		var buf bytes.Buffer
+		opts.Samples = samples
+		opts.Output = &buf
+		renderTables(m, filters, opts)
+
+		// overwrite the previous output instead of clearing the screen first,
+		// which would make it flicker
+		out.MoveCursor(1, 1)
+		eraseLine := termenv.CSI + termenv.EraseLineRightSeq
+		fmt.Fprint(os.Stdout, strings.ReplaceAll(buf.String(), "\n", eraseLine+"\n"))
+		fmt.Fprintf(os.Stdout, termenv.CSI+termenv.EraseDisplaySeq, 0)
+	}
+
+	if err := sample(); err != nil {
+		return err
+	}
+	redraw()
+
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-resize:
+			redraw()
+		case <-ticker.C:
+			if err := sample(); err != nil {
+				return err
+			}
+			redraw()
+		}
+	}
+}
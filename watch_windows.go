@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+)
+
+// notifyResize relays terminal resize signals to c. Windows has no such
+// signal, so the tables only adjust to the terminal's width on refresh.
+func notifyResize(_ chan<- os.Signal) {
+}